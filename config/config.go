@@ -0,0 +1,315 @@
+// Package config loads the shared settings for the command line from a YAML
+// file, so a team can check in a watchlist, with environment variables
+// overriding anything secret.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"eiffel65/notify"
+	"eiffel65/steam"
+)
+
+const (
+	// DefaultPath is the config file read from the working directory when no
+	// other is given.
+	DefaultPath string = "eiffel65.yaml"
+	envPrefix   string = "EIFFEL65_"
+)
+
+// CommandLineNotifiers are the names rules can notify the sinks set by flags
+// such as -discord by.
+var CommandLineNotifiers = []string{"webhook", "discord", "slack", "smtp"}
+
+// commandLineNotifier reports whether a notifier name is one of the command
+// line sinks.
+func commandLineNotifier(name string) bool {
+	for _, commandLineName := range CommandLineNotifiers {
+		if name == commandLineName {
+			return true
+		}
+	}
+	return false
+}
+
+// Config is everything that can be set in the config file.
+type Config struct {
+	SteamAPIKey string `yaml:"steam_api_key"`
+	// Currency is a currency code such as GBP, or Steam's number for it.
+	Currency string `yaml:"currency"`
+	Listings int    `yaml:"listings"`
+	// History is a SQLite database to record every listing scanned in.
+	History    string                   `yaml:"history"`
+	Providers  Providers                `yaml:"providers"`
+	RateLimits RateLimits               `yaml:"rate_limits"`
+	Watchlist  []WatchItem              `yaml:"watchlist"`
+	Rules      []steam.Rule             `yaml:"rules"`
+	Notifiers  map[string]notify.Config `yaml:"notifiers"`
+}
+
+// Providers are the base URLs of the APIs listings are looked up with.
+type Providers struct {
+	MarketURL   string `yaml:"market_url"`
+	SteamAPIURL string `yaml:"steam_api_url"`
+	FloatURL    string `yaml:"float_url"`
+}
+
+// RateLimits are the least time to leave between requests to each provider.
+type RateLimits struct {
+	Market time.Duration `yaml:"market"`
+	Float  time.Duration `yaml:"float"`
+}
+
+// WatchItem is an item to scan along with the variants of it to look up.
+type WatchItem struct {
+	Name string `yaml:"name"`
+	// Type is the item type accepted by steam.ParseAssetType.
+	Type string `yaml:"type"`
+	// Wear is the wear tiers accepted by steam.ParseWearTiers, by default "3"
+	// for weapons, knives and gloves and "0" for items without wear.
+	Wear         string `yaml:"wear"`
+	StatTrak     bool   `yaml:"stattrak"`
+	BothStatTrak bool   `yaml:"both_stattrak"`
+	Souvenir     bool   `yaml:"souvenir"`
+	Listings     int    `yaml:"listings"`
+	// Filter drops listings that do not match it before any rules are
+	// checked. It is matched before pricing and scoring, so cannot use
+	// discounts, sticker values, deal scores or profits.
+	Filter steam.Rule `yaml:"filter"`
+}
+
+// Find picks the config file to load: the given path, then the path in
+// EIFFEL65_CONFIG, then DefaultPath if it exists. An empty result means there
+// is no config file.
+func Find(path string) string {
+	if path != "" {
+		return path
+	}
+	if path, ok := os.LookupEnv(envPrefix + "CONFIG"); ok {
+		return path
+	}
+	if _, err := os.Stat(DefaultPath); err == nil {
+		return DefaultPath
+	}
+	return ""
+}
+
+// Load reads a config file, applies environment variable overrides and checks
+// the result. An empty path loads only the environment.
+func Load(path string) (*Config, error) {
+	config := Config{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse config %s: %s", path, err)
+		}
+	}
+
+	err := config.applyEnv(os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %s", path, err)
+	}
+
+	return &config, nil
+}
+
+// applyEnv overrides settings with EIFFEL65_ environment variables. Notifier
+// secrets are set per notifier, e.g. EIFFEL65_NOTIFIER_TEAM_URL.
+func (config *Config) applyEnv(lookup func(string) (string, bool)) error {
+	settings := map[string]*string{
+		"STEAM_API_KEY": &config.SteamAPIKey,
+		"CURRENCY":      &config.Currency,
+		"MARKET_URL":    &config.Providers.MarketURL,
+		"STEAM_API_URL": &config.Providers.SteamAPIURL,
+		"FLOAT_URL":     &config.Providers.FloatURL,
+		"HISTORY":       &config.History,
+	}
+	for name, setting := range settings {
+		if value, ok := lookup(envPrefix + name); ok {
+			*setting = value
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"MARKET_RATE_LIMIT": &config.RateLimits.Market,
+		"FLOAT_RATE_LIMIT":  &config.RateLimits.Float,
+	}
+	for name, setting := range durations {
+		value, ok := lookup(envPrefix + name)
+		if !ok {
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s%s: %s", envPrefix, name, err)
+		}
+		*setting = duration
+	}
+
+	for name, notifier := range config.Notifiers {
+		prefix := envPrefix + "NOTIFIER_" + envName(name) + "_"
+		if value, ok := lookup(prefix + "URL"); ok {
+			notifier.URL = value
+		}
+		if value, ok := lookup(prefix + "PASSWORD"); ok {
+			notifier.Password = value
+		}
+		config.Notifiers[name] = notifier
+	}
+
+	return nil
+}
+
+// envName converts a notifier name into the form used in environment
+// variables, e.g. "team-discord" to "TEAM_DISCORD".
+func envName(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", " ", "_", ".", "_").Replace(name))
+}
+
+// Validate checks the watchlist, rules and notifiers make sense together.
+func (config *Config) Validate() error {
+	if _, err := config.CurrencyCode(); err != nil {
+		return err
+	}
+
+	for i, item := range config.Watchlist {
+		if item.Name == "" {
+			return fmt.Errorf("watchlist item %d needs a name", i+1)
+		}
+		if _, _, err := item.Query(); err != nil {
+			return fmt.Errorf("watchlist item %q: %s", item.Name, err)
+		}
+		filter := item.Filter
+		if filter.MinDiscount != 0 || filter.MinStickerValue != 0 || filter.MinDealScore != 0 || filter.MinComparableDiscount != 0 || filter.MinProfit != 0 {
+			return fmt.Errorf("watchlist item %q: filters cannot use prices, deal scores or profits", item.Name)
+		}
+		if item.Filter.MaxFloat != 0 && item.Filter.MinFloat > item.Filter.MaxFloat {
+			return fmt.Errorf("watchlist item %q has a min_float above its max_float", item.Name)
+		}
+	}
+
+	err := steam.ValidateRules(config.Rules)
+	if err != nil {
+		return err
+	}
+	// The command line sinks are only known once the flags are read, so
+	// whether they were set is checked when the alert sinks are created.
+	for _, rule := range config.Rules {
+		for _, name := range rule.Notify {
+			if _, ok := config.Notifiers[name]; !ok && !commandLineNotifier(name) {
+				return fmt.Errorf("rule %q notifies unknown notifier %q, expected one of notifiers or a command line sink: %s",
+					rule.Name, name, strings.Join(CommandLineNotifiers, ", "))
+			}
+		}
+	}
+
+	for name, notifier := range config.Notifiers {
+		if _, err := notify.New(notifier); err != nil {
+			return fmt.Errorf("notifier %q: %s", name, err)
+		}
+	}
+
+	return nil
+}
+
+// CurrencyCode is Steam's number for the configured currency, empty when it
+// is not set.
+func (config *Config) CurrencyCode() (string, error) {
+	if config.Currency == "" {
+		return "", nil
+	}
+	if code, ok := steam.CurrencyCode(config.Currency); ok {
+		return code, nil
+	}
+	return "", fmt.Errorf("unknown currency %q", config.Currency)
+}
+
+// NewClient creates a Steam client using the configured providers, currency
+// and rate limits. The API key is passed in so a flag can override it.
+func (config *Config) NewClient(apiKey string) *steam.Client {
+	client := steam.NewClient(apiKey)
+	if config.Providers.MarketURL != "" {
+		client.MarketBaseURL = strings.TrimSuffix(config.Providers.MarketURL, "/")
+	}
+	if config.Providers.SteamAPIURL != "" {
+		client.APIBaseURL = strings.TrimSuffix(config.Providers.SteamAPIURL, "/")
+	}
+	if config.Providers.FloatURL != "" {
+		client.FloatBaseURL = config.Providers.FloatURL
+	}
+	if code, _ := config.CurrencyCode(); code != "" {
+		client.Currency = code
+	}
+	client.MarketRateLimit = config.RateLimits.Market
+	client.FloatRateLimit = config.RateLimits.Float
+	return client
+}
+
+// NewRouter creates the configured notifiers, routing each rule's alerts to
+// the notifiers it names.
+func (config *Config) NewRouter(rules []steam.Rule) (*notify.Router, error) {
+	router := &notify.Router{
+		Notifiers: map[string]notify.Notifier{},
+		Routes:    map[string][]string{},
+	}
+	for name, notifierConfig := range config.Notifiers {
+		notifier, err := notify.New(notifierConfig)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %q: %s", name, err)
+		}
+		router.Notifiers[name] = notifier
+	}
+	for _, rule := range rules {
+		if len(rule.Notify) > 0 {
+			router.Routes[rule.Name] = rule.Notify
+		}
+	}
+	return router, nil
+}
+
+// Query is the item type and variants to look up for a watchlist item.
+func (item WatchItem) Query() (steam.AssetType, []steam.AssetVariant, error) {
+	assetType, err := steam.ParseAssetType(item.Type)
+	if err != nil {
+		return "", nil, err
+	}
+
+	wear := item.Wear
+	if wear == "" {
+		wear = "0"
+		if assetType.HasWear() {
+			wear = "3"
+		}
+	}
+	wearTiers, err := steam.ParseWearTiers(wear)
+	if err != nil {
+		return "", nil, err
+	}
+
+	statTrakOptions := []bool{item.StatTrak}
+	if item.BothStatTrak {
+		statTrakOptions = []bool{false, true}
+	}
+
+	return assetType, steam.NewVariants(wearTiers, item.Souvenir, statTrakOptions...), nil
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"eiffel65/notify"
+	"eiffel65/steam"
+)
+
+func TestLoad(t *testing.T) {
+	config, err := Load(filepath.Join("testdata", "eiffel65.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if config.Listings != 50 || config.RateLimits.Market != 3*time.Second || config.RateLimits.Float != 500*time.Millisecond {
+		t.Errorf("got %+v", config)
+	}
+	if len(config.Watchlist) != 2 || config.Watchlist[0].Filter.MaxFloat != 0.3 || config.Watchlist[1].Type != "knife" {
+		t.Errorf("got watchlist %+v", config.Watchlist)
+	}
+	if len(config.Rules) != 2 || config.Rules[0].Seeds[2] != 387 || config.Rules[0].Notify[0] != "team" {
+		t.Errorf("got rules %+v", config.Rules)
+	}
+	if config.Notifiers["personal"].To[0] != "me@example.com" {
+		t.Errorf("got notifiers %+v", config.Notifiers)
+	}
+
+	client := config.NewClient("key")
+	if client.Currency != "3" || client.FloatBaseURL != "https://float.example.com/" || client.MarketRateLimit != 3*time.Second {
+		t.Errorf("got client %+v", client)
+	}
+	if name := steam.CurrencyName(client.Currency); name != "EUR" {
+		t.Errorf("got currency %s, want EUR", name)
+	}
+
+	router, err := config.NewRouter(config.Rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(router.Notifiers) != 2 || router.Routes["blue gems"][0] != "team" || router.Routes["low float"] != nil {
+		t.Errorf("got router %+v", router)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	config := Config{Notifiers: map[string]notify.Config{"team-discord": {Type: "discord"}}}
+	env := map[string]string{
+		"EIFFEL65_STEAM_API_KEY":              "secret",
+		"EIFFEL65_MARKET_RATE_LIMIT":          "2s",
+		"EIFFEL65_HISTORY":                    "/var/lib/eiffel65/history.db",
+		"EIFFEL65_NOTIFIER_TEAM_DISCORD_URL":  "https://discord.example.com/webhook",
+		"EIFFEL65_NOTIFIER_TEAM_DISCORD_PASS": "ignored",
+	}
+	err := config.applyEnv(func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if config.SteamAPIKey != "secret" || config.RateLimits.Market != 2*time.Second || config.History != "/var/lib/eiffel65/history.db" {
+		t.Errorf("got %+v", config)
+	}
+	if config.Notifiers["team-discord"].URL != "https://discord.example.com/webhook" {
+		t.Errorf("got notifiers %+v", config.Notifiers)
+	}
+
+	err = config.applyEnv(func(name string) (string, bool) { return "soon", name == "EIFFEL65_FLOAT_RATE_LIMIT" })
+	if err == nil {
+		t.Error("expected an error for an invalid duration")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for name, yaml := range map[string]string{
+		"unknown field":    "stem_api_key: typo\n",
+		"unknown currency": "currency: XYZ\n",
+		"unnamed item":     "watchlist:\n  - wear: 1\n",
+		"bad wear":         "watchlist:\n  - name: AK-47 | Redline\n    wear: 9\n",
+		"priced filter":    "watchlist:\n  - name: AK-47 | Redline\n    filter:\n      min_discount: 10\n",
+		"unknown notifier": "rules:\n  - name: cheap\n    max_price: 5\n    notify: [pager]\n",
+		"bad notifier":     "notifiers:\n  team:\n    type: discord\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "eiffel65.yaml")
+			err := os.WriteFile(path, []byte(yaml), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestCommandLineNotifiers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eiffel65.yaml")
+	err := os.WriteFile(path, []byte("rules:\n  - name: cheap\n    max_price: 5\n    notify: [discord, smtp]\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err != nil {
+		t.Errorf("expected rules to notify the command line sinks, got %s", err)
+	}
+}
+
+func TestWatchItemQuery(t *testing.T) {
+	assetType, variants, err := WatchItem{Name: "Karambit | Case Hardened", Type: "knife", Wear: "1,2", BothStatTrak: true}.Query()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if assetType != "knife" || len(variants) != 4 {
+		t.Errorf("got %s with %v", assetType, variants)
+	}
+
+	_, variants, err = WatchItem{Name: "AK-47 | Redline"}.Query()
+	if err != nil || len(variants) != 1 || variants[0] != (steam.AssetVariant{Wear: "Field-Tested"}) {
+		t.Errorf("got %v, %v, want Field-Tested by default", variants, err)
+	}
+
+	// Items without wear are not given one by default.
+	for _, itemType := range []string{"sticker", "agent", "case"} {
+		_, variants, err = WatchItem{Name: "Revolution Case", Type: itemType}.Query()
+		if err != nil || len(variants) != 1 || variants[0] != (steam.AssetVariant{}) {
+			t.Errorf("%s: got %v, %v, want no wear by default", itemType, variants, err)
+		}
+	}
+}
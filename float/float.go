@@ -3,13 +3,13 @@ package float
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 )
 
-const (
-	csgoFloatBaseURL string = "https://api.csgofloat.com/"
-)
+// BaseURL is the default float API to look inspect links up with.
+const BaseURL string = "https://api.csgofloat.com/"
 
 // AssetFloatPayload contains the payload of data on asset quality and appearance.
 type AssetFloatPayload struct {
@@ -35,6 +35,21 @@ type AssetFloat struct {
 	ItemName   string    `json:"item_name,omitempty"`
 }
 
+// Fingerprint identifies the exact item behind an asset across trades and
+// relistings, which change its asset ID, e.g. "7-44-661-1046887630". The
+// float is compared by its raw paint wear, falling back to the float value's
+// bits when the API leaves it out. It is empty for items not yet inspected.
+func (assetFloat AssetFloat) Fingerprint() string {
+	if assetFloat.DefIndex == 0 {
+		return ""
+	}
+	paintWear := assetFloat.PaintWear
+	if paintWear == 0 && assetFloat.FloatValue != 0 {
+		paintWear = int64(math.Float32bits(float32(assetFloat.FloatValue)))
+	}
+	return fmt.Sprintf("%d-%d-%d-%d", assetFloat.DefIndex, assetFloat.PaintIndex, assetFloat.PaintSeed, paintWear)
+}
+
 // Sticker is an asset that can be attached to CSGO weapons.
 type Sticker struct {
 	Slot      int     `json:"slot,omitempty"`
@@ -48,19 +63,34 @@ type Sticker struct {
 
 // Get looks up the asset paint/design quality.
 func Get(inspectURL string) (*AssetFloatPayload, string, error) {
-	csgoFloatURL, err := url.Parse(fmt.Sprintf("%s?url=%s", csgoFloatBaseURL, inspectURL))
+	return GetFrom(BaseURL, inspectURL)
+}
+
+// GetFrom looks up the asset paint/design quality from another float API.
+func GetFrom(baseURL, inspectURL string) (*AssetFloatPayload, string, error) {
+	return GetWith(http.DefaultClient, baseURL, inspectURL)
+}
+
+// GetWith looks up the asset paint/design quality from a float API with the
+// given HTTP client.
+func GetWith(httpClient *http.Client, baseURL, inspectURL string) (*AssetFloatPayload, string, error) {
+	csgoFloatURL, err := url.Parse(fmt.Sprintf("%s?url=%s", baseURL, inspectURL))
 	if err != nil {
 		return nil, csgoFloatURL.String(), err
 	}
 
 	//log.Println(csgoFloatURL)
 
-	response, err := http.DefaultClient.Get(csgoFloatURL.String())
+	response, err := httpClient.Get(csgoFloatURL.String())
 	if err != nil {
 		return nil, csgoFloatURL.String(), err
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode != http.StatusOK {
+		return nil, csgoFloatURL.String(), fmt.Errorf("float API: HTTP %d", response.StatusCode)
+	}
+
 	assetFloatPayload := AssetFloatPayload{}
 	err = json.NewDecoder(response.Body).Decode(&assetFloatPayload)
 	if err != nil {
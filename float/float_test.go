@@ -0,0 +1,65 @@
+package float
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name       string
+		assetFloat AssetFloat
+		want       string
+	}{
+		{"paint wear", AssetFloat{DefIndex: 7, PaintIndex: 44, PaintSeed: 661, PaintWear: 1046887630, FloatValue: 0.2113}, "7-44-661-1046887630"},
+		{"float value", AssetFloat{DefIndex: 7, PaintIndex: 44, PaintSeed: 661, FloatValue: 0.25}, "7-44-661-1048576000"},
+		{"not inspected", AssetFloat{}, ""},
+	}
+
+	for _, test := range tests {
+		if got := test.assetFloat.Fingerprint(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	// The paint wear is the bits of the float, so both ways agree.
+	withWear := AssetFloat{DefIndex: 7, PaintIndex: 44, PaintSeed: 1, PaintWear: int64(math.Float32bits(0.07))}
+	withFloat := AssetFloat{DefIndex: 7, PaintIndex: 44, PaintSeed: 1, FloatValue: float64(float32(0.07))}
+	if withWear.Fingerprint() != withFloat.Fingerprint() {
+		t.Errorf("got %q and %q", withWear.Fingerprint(), withFloat.Fingerprint())
+	}
+}
+
+func TestGetWith(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   int
+		err    bool
+	}{
+		{http.StatusOK, `{"iteminfo": {"defindex": 7, "paintindex": 44, "paintseed": 661}}`, 661, false},
+		// The float API answers failed inspects with an error body, which
+		// must not be read as an item without a float.
+		{http.StatusGatewayTimeout, `{"error": "Valve's servers didn't reply in time", "code": 4}`, 0, true},
+		{http.StatusTooManyRequests, `{"error": "Rate limit exceeded", "code": 6}`, 0, true},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+			w.Write([]byte(test.body))
+		}))
+
+		payload, _, err := GetWith(server.Client(), server.URL+"/", "steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20S1A2D3")
+		server.Close()
+		if (err != nil) != test.err {
+			t.Errorf("%d: got error %v, want error %t", test.status, err, test.err)
+			continue
+		}
+		if err == nil && payload.ItemInfo.PaintSeed != test.want {
+			t.Errorf("%d: got seed %d, want %d", test.status, payload.ItemInfo.PaintSeed, test.want)
+		}
+	}
+}
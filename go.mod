@@ -1,5 +1,21 @@
 module eiffel65
 
-go 1.17
+go 1.21
 
-require github.com/jtrotsky/eiffel65 v0.0.0-20191126222502-18509a254545
+require (
+	github.com/mattn/go-sqlite3 v1.14.17
+	github.com/prometheus/client_golang v1.16.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/matttproud/golang_protobuf_extensions v1.0.4 // indirect
+	github.com/prometheus/client_model v0.3.0 // indirect
+	github.com/prometheus/common v0.42.0 // indirect
+	github.com/prometheus/procfs v0.10.1 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+	google.golang.org/protobuf v1.30.0 // indirect
+)
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"eiffel65/history"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runHistory looks back on the listings recorded in the history database,
+// such as when a seed last sold and for how much.
+func runHistory(flags *flag.FlagSet, args []string) error {
+	clientFlags := clientOptions{}
+	clientFlags.register(flags)
+	historyFlags := historyOptions{}
+	historyFlags.register(flags)
+	name := flags.String("n", "", "only listings whose name contains this, e.g. \"Case Hardened\"")
+	seeds := flags.String("seed", "", "only listings with these paint seeds, e.g. 661,151")
+	sold := flags.Bool("sold", false, "only listings that have gone from the market without being relisted, the probable sales")
+	since := flags.Duration("since", 0, "only listings seen or gone within this long, e.g. 720h")
+	limit := flags.Int("limit", 0, "print at most this many listings, most recent first, 0 for all")
+	tiers := flags.Bool("tiers", false, "summarise the prices of the probable sales by pattern tier instead")
+	sales := flags.Bool("sales", false, "log the probable sales by item, seed and float range instead, leaving out relisted items")
+	floatBucket := flags.Float64("bucket", history.DefaultFloatBucket, "how wide the float ranges of -sales are")
+	fingerprint := flags.String("item", "", "follow one exact item by its fingerprint, e.g. 7-44-661-1046887630, through every time it was listed instead")
+	flags.Parse(args)
+
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", flags.Args())
+	}
+
+	settings, err := clientFlags.load()
+	if err != nil {
+		return err
+	}
+
+	db, err := historyFlags.open(settings)
+	if err != nil {
+		return err
+	}
+	if db == nil {
+		return fmt.Errorf("please specify the -history database")
+	}
+	defer db.Close()
+
+	query := history.Query{Name: *name, Gone: *sold, Limit: *limit}
+	if *since > 0 {
+		query.Since = time.Now().Add(-*since)
+	}
+	for _, field := range strings.Split(*seeds, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		seed, err := strconv.Atoi(field)
+		if err != nil {
+			return fmt.Errorf("invalid seed %q", field)
+		}
+		query.Seeds = append(query.Seeds, seed)
+	}
+
+	if *fingerprint != "" {
+		provenance, err := db.Provenance(*fingerprint)
+		if err != nil {
+			return err
+		}
+		return printJSON(provenance)
+	}
+
+	if *sales {
+		salesLog, err := db.SalesLog(query, *floatBucket)
+		if err != nil {
+			return fmt.Errorf("failed to log the sales: %s", err)
+		}
+		return printJSON(salesLog)
+	}
+
+	if *tiers {
+		tierPrices, err := db.TierPrices(query)
+		if err != nil {
+			return fmt.Errorf("failed to summarise the history: %s", err)
+		}
+		return printJSON(tierPrices)
+	}
+
+	listings, err := db.Listings(query)
+	if err != nil {
+		return fmt.Errorf("failed to look up the history: %s", err)
+	}
+	return printJSON(listings)
+}
@@ -0,0 +1,406 @@
+// Package history keeps every listing seen on the market in a SQLite
+// database, so that prices and probable sales can be looked back on long
+// after the scan that found them.
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// Registers the sqlite3 driver.
+	_ "github.com/mattn/go-sqlite3"
+
+	"eiffel65/steam"
+)
+
+const schemaSQL string = `
+CREATE TABLE IF NOT EXISTS listings (
+	listing_id  TEXT PRIMARY KEY,
+	asset_id    TEXT NOT NULL,
+	name        TEXT NOT NULL,
+	def_index   INTEGER NOT NULL,
+	paint_index INTEGER NOT NULL,
+	paint_seed  INTEGER NOT NULL,
+	paint_wear  INTEGER NOT NULL,
+	float_value REAL NOT NULL,
+	rarity_tier INTEGER NOT NULL,
+	price       REAL NOT NULL,
+	currency    TEXT NOT NULL,
+	inspect_url TEXT NOT NULL,
+	first_seen  INTEGER NOT NULL,
+	last_seen   INTEGER NOT NULL,
+	gone        INTEGER
+);
+CREATE INDEX IF NOT EXISTS listings_by_seed ON listings (def_index, paint_seed);
+CREATE INDEX IF NOT EXISTS listings_by_name ON listings (name, gone);
+`
+
+// migrations update databases made by older versions, each one run once in
+// order and counted in the user_version.
+var migrations = []string{
+	`ALTER TABLE listings ADD COLUMN relisted_as TEXT;
+	CREATE INDEX IF NOT EXISTS listings_by_asset ON listings (asset_id);`,
+	// Listings recorded without a paint wear are left without a fingerprint,
+	// as SQLite cannot take the bits of their float.
+	`ALTER TABLE listings ADD COLUMN fingerprint TEXT;
+	UPDATE listings SET fingerprint = def_index || '-' || paint_index || '-' || paint_seed || '-' || paint_wear
+	WHERE def_index != 0 AND paint_wear != 0;
+	CREATE INDEX IF NOT EXISTS listings_by_fingerprint ON listings (fingerprint);`,
+}
+
+// listingColumns are the columns read into a Listing, in order.
+const listingColumns string = `listing_id, asset_id, name, def_index, paint_index, paint_seed,
+	paint_wear, float_value, rarity_tier, price, currency, inspect_url, first_seen, last_seen, gone, relisted_as, fingerprint`
+
+// DB is a listing history database.
+type DB struct {
+	db *sql.DB
+}
+
+// Listing is a listing as it was last seen on the market. Times are stored
+// to the second.
+type Listing struct {
+	ListingID  string    `json:"listing_id"`
+	AssetID    string    `json:"asset_id"`
+	Name       string    `json:"name"`
+	DefIndex   int       `json:"def_index"`
+	PaintIndex int       `json:"paint_index"`
+	PaintSeed  int       `json:"paint_seed"`
+	PaintWear  int64     `json:"paint_wear"`
+	FloatValue float64   `json:"float_value"`
+	RarityTier int       `json:"rarity_tier,omitempty"`
+	Price      float64   `json:"price"`
+	Currency   string    `json:"currency"`
+	InspectURL string    `json:"inspect_url,omitempty"`
+	FirstSeen  time.Time `json:"first_seen"`
+	LastSeen   time.Time `json:"last_seen"`
+	// Gone is when the listing vanished from a scan that should have shown
+	// it, most likely because it sold.
+	Gone *time.Time `json:"gone,omitempty"`
+	// RelistedAs is the listing the same asset turned up in after this one
+	// was gone, as it was relisted at a new price rather than sold.
+	RelistedAs string `json:"relisted_as,omitempty"`
+	// Fingerprint identifies the exact item across trades and relistings,
+	// see float.AssetFloat.Fingerprint.
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// Query narrows down the listings looked up.
+type Query struct {
+	// Name matches listings whose market name contains it, ignoring case.
+	Name string
+	// Seeds matches any of the paint seeds.
+	Seeds []int
+	// Fingerprint matches every listing of one exact item.
+	Fingerprint string
+	// Gone matches only the listings that have vanished without being
+	// relisted, the probable sales.
+	Gone bool
+	// Since matches listings last seen, or gone, at or after it.
+	Since time.Time
+	// Limit is the most listings to return, zero for all.
+	Limit int
+}
+
+// TierPrices are the prices probable sales went for in a pattern tier.
+type TierPrices struct {
+	Tier    int     `json:"tier"`
+	Sales   int     `json:"sales"`
+	Lowest  float64 `json:"lowest"`
+	Median  float64 `json:"median"`
+	Highest float64 `json:"highest"`
+}
+
+// Open opens the history database at the path, creating it if needed.
+func Open(path string) (*DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite only has one writer, so share one connection rather than
+	// failing with busy errors.
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(schemaSQL)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create history database %s: %s", path, err)
+	}
+
+	err = migrate(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to update history database %s: %s", path, err)
+	}
+
+	return &DB{db: db}, nil
+}
+
+// migrate runs the migrations the database has not had yet.
+func migrate(db *sql.DB) error {
+	version := 0
+	err := db.QueryRow("PRAGMA user_version").Scan(&version)
+	if err != nil {
+		return err
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(migrations[version])
+		if err == nil {
+			_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1))
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %s", version+1, err)
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the database.
+func (db *DB) Close() error {
+	return db.db.Close()
+}
+
+// Record stores the listings found by a scan at the time, updating those
+// seen before. Listings of the scanned items that are missing from it are
+// marked gone, unless they were priced above every listing in the scan and
+// so may only have been pushed off the page. A gone listing whose asset turns
+// up again under a new listing ID was relisted rather than sold, as the asset
+// ID changes when an item is traded.
+func (db *DB) Record(assetList []steam.SimpleAsset, seen time.Time) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	upsert, err := tx.Prepare(`
+		INSERT INTO listings (` + listingColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NULL, NULL, ?)
+		ON CONFLICT (listing_id) DO UPDATE SET
+			asset_id = excluded.asset_id,
+			price = excluded.price,
+			currency = excluded.currency,
+			last_seen = excluded.last_seen,
+			gone = NULL,
+			relisted_as = NULL,
+			fingerprint = COALESCE(excluded.fingerprint, fingerprint)`)
+	if err != nil {
+		return err
+	}
+	defer upsert.Close()
+
+	// Earlier listings of the same asset were relisted, whether or not they
+	// have been marked gone yet.
+	relist, err := tx.Prepare(`
+		UPDATE listings SET relisted_as = ?, gone = COALESCE(gone, ?)
+		WHERE asset_id = ? AND listing_id != ? AND relisted_as IS NULL AND last_seen < ?`)
+	if err != nil {
+		return err
+	}
+	defer relist.Close()
+
+	// The highest price of each item scanned, above which missing listings
+	// may still be listed.
+	highestPrices := map[string]float64{}
+
+	for _, asset := range assetList {
+		if asset.ListingID == "" {
+			continue
+		}
+
+		price, err := strconv.ParseFloat(asset.ListingTotalPrice, 64)
+		if err != nil {
+			return fmt.Errorf("invalid price %q for listing %s", asset.ListingTotalPrice, asset.ListingID)
+		}
+		if price > highestPrices[asset.Name] {
+			highestPrices[asset.Name] = price
+		}
+
+		_, err = upsert.Exec(
+			asset.ListingID,
+			asset.ID,
+			asset.Name,
+			asset.Float.DefIndex,
+			asset.Float.PaintIndex,
+			asset.Float.PaintSeed,
+			asset.Float.PaintWear,
+			asset.Float.FloatValue,
+			asset.RarityTier,
+			price,
+			asset.ListingCurrency,
+			asset.InspectURL,
+			seen.Unix(),
+			seen.Unix(),
+			sql.NullString{String: asset.Float.Fingerprint(), Valid: asset.Float.Fingerprint() != ""},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record listing %s: %s", asset.ListingID, err)
+		}
+
+		_, err = relist.Exec(asset.ListingID, seen.Unix(), asset.ID, asset.ListingID, seen.Unix())
+		if err != nil {
+			return fmt.Errorf("failed to check listing %s for relists: %s", asset.ListingID, err)
+		}
+	}
+
+	for name, highestPrice := range highestPrices {
+		_, err = tx.Exec(`
+			UPDATE listings SET gone = ?
+			WHERE name = ? AND gone IS NULL AND last_seen < ? AND price <= ?`,
+			seen.Unix(), name, seen.Unix(), highestPrice)
+		if err != nil {
+			return fmt.Errorf("failed to mark gone listings of %s: %s", name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Listings looks up the listings matching the query, most recently seen
+// first.
+func (db *DB) Listings(query Query) ([]Listing, error) {
+	where, args := query.where()
+	limit := ""
+	if query.Limit > 0 {
+		limit = fmt.Sprintf(" LIMIT %d", query.Limit)
+	}
+
+	rows, err := db.db.Query(`SELECT `+listingColumns+` FROM listings`+where+
+		` ORDER BY COALESCE(gone, last_seen) DESC, listing_id`+limit, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	listings := []Listing{}
+	for rows.Next() {
+		listing := Listing{}
+		firstSeen, lastSeen, gone := int64(0), int64(0), sql.NullInt64{}
+		relistedAs, fingerprint := sql.NullString{}, sql.NullString{}
+		err := rows.Scan(
+			&listing.ListingID,
+			&listing.AssetID,
+			&listing.Name,
+			&listing.DefIndex,
+			&listing.PaintIndex,
+			&listing.PaintSeed,
+			&listing.PaintWear,
+			&listing.FloatValue,
+			&listing.RarityTier,
+			&listing.Price,
+			&listing.Currency,
+			&listing.InspectURL,
+			&firstSeen,
+			&lastSeen,
+			&gone,
+			&relistedAs,
+			&fingerprint,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		listing.FirstSeen = time.Unix(firstSeen, 0).UTC()
+		listing.LastSeen = time.Unix(lastSeen, 0).UTC()
+		if gone.Valid {
+			goneTime := time.Unix(gone.Int64, 0).UTC()
+			listing.Gone = &goneTime
+		}
+		listing.RelistedAs = relistedAs.String
+		listing.Fingerprint = fingerprint.String
+		listings = append(listings, listing)
+	}
+	return listings, rows.Err()
+}
+
+// TierPrices summarises the prices of the probable sales matching the query
+// by pattern tier, leaving out listings without a tier.
+func (db *DB) TierPrices(query Query) ([]TierPrices, error) {
+	query.Gone = true
+	query.Limit = 0
+	listings, err := db.Listings(query)
+	if err != nil {
+		return nil, err
+	}
+
+	pricesByTier := map[int][]float64{}
+	for _, listing := range listings {
+		if listing.RarityTier > 0 {
+			pricesByTier[listing.RarityTier] = append(pricesByTier[listing.RarityTier], listing.Price)
+		}
+	}
+
+	tierPrices := []TierPrices{}
+	for tier, prices := range pricesByTier {
+		sort.Float64s(prices)
+		tierPrices = append(tierPrices, TierPrices{
+			Tier:    tier,
+			Sales:   len(prices),
+			Lowest:  prices[0],
+			Median:  median(prices),
+			Highest: prices[len(prices)-1],
+		})
+	}
+	sort.Slice(tierPrices, func(i, j int) bool {
+		return tierPrices[i].Tier < tierPrices[j].Tier
+	})
+	return tierPrices, nil
+}
+
+// where builds the WHERE clause of the query along with its arguments.
+func (query Query) where() (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if query.Name != "" {
+		conditions = append(conditions, "name LIKE ?")
+		args = append(args, "%"+query.Name+"%")
+	}
+	if len(query.Seeds) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(query.Seeds)), ", ")
+		conditions = append(conditions, "paint_seed IN ("+placeholders+")")
+		for _, seed := range query.Seeds {
+			args = append(args, seed)
+		}
+	}
+	if query.Fingerprint != "" {
+		conditions = append(conditions, "fingerprint = ?")
+		args = append(args, query.Fingerprint)
+	}
+	if query.Gone {
+		conditions = append(conditions, "gone IS NOT NULL AND relisted_as IS NULL")
+	}
+	if !query.Since.IsZero() {
+		conditions = append(conditions, "COALESCE(gone, last_seen) >= ?")
+		args = append(args, query.Since.Unix())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// median is the middle of the sorted prices.
+func median(prices []float64) float64 {
+	middle := len(prices) / 2
+	if len(prices)%2 == 0 {
+		return (prices[middle-1] + prices[middle]) / 2
+	}
+	return prices[middle]
+}
@@ -0,0 +1,217 @@
+package history
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"eiffel65/float"
+	"eiffel65/steam"
+)
+
+const caseHardened = "AK-47 | Case Hardened (Field-Tested)"
+
+func listing(listingID, assetID, price string, seed, tier int) steam.SimpleAsset {
+	return steam.SimpleAsset{
+		ID:                assetID,
+		ListingID:         listingID,
+		Name:              caseHardened,
+		ListingTotalPrice: price,
+		ListingCurrency:   "2",
+		RarityTier:        tier,
+		Float:             float.AssetFloat{DefIndex: 7, PaintIndex: 44, PaintSeed: seed, FloatValue: 0.25},
+	}
+}
+
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestRecord(t *testing.T) {
+	db := openTestDB(t)
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	scans := [][]steam.SimpleAsset{
+		{
+			listing("101", "11", "100.00", 661, 1),
+			listing("102", "12", "50.00", 12, 0),
+			listing("103", "13", "120.00", 151, 1),
+			listing("104", "14", "400.00", 555, 3),
+		},
+		// 101 sells, 102 is repriced and 104 is pushed off the page by a
+		// cheaper listing.
+		{
+			listing("102", "12", "45.00", 12, 0),
+			listing("103", "13", "120.00", 151, 1),
+			listing("105", "15", "60.00", 20, 0),
+		},
+	}
+	for i, scan := range scans {
+		err := db.Record(scan, start.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("scan %d: %s", i, err)
+		}
+	}
+
+	listings, err := db.Listings(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]Listing{}
+	for _, listing := range listings {
+		byID[listing.ListingID] = listing
+	}
+	if len(byID) != 5 {
+		t.Fatalf("got %d listings, want 5", len(byID))
+	}
+
+	if gone := byID["101"].Gone; gone == nil || !gone.Equal(start.Add(time.Minute)) {
+		t.Errorf("expected 101 to be gone at the second scan, got %v", gone)
+	}
+	if byID["104"].Gone != nil {
+		t.Error("expected 104, priced above the second scan, not to be gone")
+	}
+	if repriced := byID["102"]; repriced.Price != 45 || !repriced.FirstSeen.Equal(start) || !repriced.LastSeen.Equal(start.Add(time.Minute)) {
+		t.Errorf("got %+v", repriced)
+	}
+}
+
+func TestListingsQuery(t *testing.T) {
+	db := openTestDB(t)
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	scans := [][]steam.SimpleAsset{
+		{listing("101", "11", "900.00", 661, 1), listing("102", "12", "1100.00", 661, 1), listing("103", "13", "2000.00", 151, 1), listing("104", "14", "5000.00", 20, 0)},
+		{listing("102", "12", "1100.00", 661, 1), listing("104", "14", "5000.00", 20, 0)},
+		{listing("104", "14", "5000.00", 20, 0)},
+	}
+	for i, scan := range scans {
+		err := db.Record(scan, start.Add(time.Duration(i)*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"last sale of a seed", Query{Seeds: []int{661}, Gone: true, Limit: 1}, []string{"102"}},
+		{"every sale", Query{Gone: true}, []string{"102", "101", "103"}},
+		{"since", Query{Gone: true, Since: start.Add(2 * time.Hour)}, []string{"102"}},
+		{"name", Query{Name: "case hardened", Seeds: []int{20}}, []string{"104"}},
+		{"other name", Query{Name: "Redline"}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			listings, err := db.Listings(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, listing := range listings {
+				got = append(got, listing.ListingID)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Fatalf("got %v, want %v", got, test.want)
+				}
+			}
+		})
+	}
+
+	tierPrices, err := db.TierPrices(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TierPrices{Tier: 1, Sales: 3, Lowest: 900, Median: 1100, Highest: 2000}
+	if len(tierPrices) != 1 || tierPrices[0] != want {
+		t.Errorf("got %+v, want %+v", tierPrices, want)
+	}
+}
+
+func TestRecordRelists(t *testing.T) {
+	db := openTestDB(t)
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	scans := [][]steam.SimpleAsset{
+		{listing("101", "11", "100.00", 661, 1), listing("102", "12", "200.00", 12, 0), listing("103", "13", "300.00", 20, 0)},
+		// 101 is relisted cheaper straight away and 102 sells.
+		{listing("201", "11", "90.00", 661, 1), listing("103", "13", "300.00", 20, 0)},
+		// 103 is taken down and marked gone, only to be relisted a scan later.
+		{listing("201", "11", "90.00", 661, 1), listing("104", "14", "350.00", 30, 0)},
+		{listing("201", "11", "90.00", 661, 1), listing("104", "14", "350.00", 30, 0), listing("203", "13", "320.00", 20, 0)},
+	}
+	for i, scan := range scans {
+		err := db.Record(scan, start.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("scan %d: %s", i, err)
+		}
+	}
+
+	listings, err := db.Listings(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]Listing{}
+	for _, listing := range listings {
+		byID[listing.ListingID] = listing
+	}
+
+	if relisted := byID["101"]; relisted.RelistedAs != "201" || relisted.Gone == nil || !relisted.Gone.Equal(start.Add(time.Minute)) {
+		t.Errorf("expected 101 to be relisted as 201 at the second scan, got %+v", relisted)
+	}
+	if relisted := byID["103"]; relisted.RelistedAs != "203" || relisted.Gone == nil || !relisted.Gone.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("expected 103 to keep when it was gone and be relisted as 203, got %+v", relisted)
+	}
+
+	sold, err := db.Listings(Query{Gone: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sold) != 1 || sold[0].ListingID != "102" {
+		t.Errorf("expected only 102 to have sold, got %+v", sold)
+	}
+}
+
+func TestOpenMigrates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	// A database from before relists were tracked.
+	old, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = old.Exec(schemaSQL)
+	if err == nil {
+		_, err = old.Exec(`INSERT INTO listings VALUES ('101', '11', ?, 7, 44, 661, 1046887630, 0.25, 1, 100, '2', '', 1, 1, 2)`, caseHardened)
+	}
+	old.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		db, err := Open(path)
+		if err != nil {
+			t.Fatalf("open %d: %s", i, err)
+		}
+		listings, err := db.Listings(Query{Gone: true})
+		db.Close()
+		if err != nil || len(listings) != 1 || listings[0].RelistedAs != "" || listings[0].Fingerprint != "7-44-661-1046887630" {
+			t.Fatalf("open %d: got %+v, %v", i, listings, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Provenance is every time one exact item has been seen on the market,
+// oldest first.
+type Provenance struct {
+	Fingerprint string  `json:"fingerprint"`
+	Name        string  `json:"name"`
+	PaintSeed   int     `json:"paint_seed"`
+	FloatValue  float64 `json:"float_value"`
+	// Owners counts the asset IDs the item has had, as they change whenever
+	// it is traded.
+	Owners       int       `json:"owners"`
+	Sales        int       `json:"sales"`
+	Relists      int       `json:"relists"`
+	LowestPrice  float64   `json:"lowest_price"`
+	HighestPrice float64   `json:"highest_price"`
+	Appearances  []Listing `json:"appearances"`
+	// Flips are the sales of the item that its new owner listed again.
+	Flips []Flip `json:"flips,omitempty"`
+}
+
+// Flip is an item bought off the market and listed again by its buyer.
+type Flip struct {
+	Bought    string    `json:"bought"`
+	BoughtFor float64   `json:"bought_for"`
+	BoughtAt  time.Time `json:"bought_at"`
+	Listed    string    `json:"listed"`
+	ListedFor float64   `json:"listed_for"`
+	ListedAt  time.Time `json:"listed_at"`
+	// Markup is how much more, as a percentage, it was listed for than it
+	// was bought for, or 0 if it was bought without a price.
+	Markup float64 `json:"markup"`
+}
+
+// Provenance looks up every listing of the item with the fingerprint, to
+// follow its price as it changes hands.
+func (db *DB) Provenance(fingerprint string) (*Provenance, error) {
+	appearances, err := db.Listings(Query{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+	if len(appearances) == 0 {
+		return nil, fmt.Errorf("no listings of %s in the history", fingerprint)
+	}
+
+	sort.Slice(appearances, func(i, j int) bool {
+		if !appearances[i].FirstSeen.Equal(appearances[j].FirstSeen) {
+			return appearances[i].FirstSeen.Before(appearances[j].FirstSeen)
+		}
+		return appearances[i].ListingID < appearances[j].ListingID
+	})
+
+	last := appearances[len(appearances)-1]
+	provenance := Provenance{
+		Fingerprint:  fingerprint,
+		Name:         last.Name,
+		PaintSeed:    last.PaintSeed,
+		FloatValue:   last.FloatValue,
+		LowestPrice:  appearances[0].Price,
+		HighestPrice: appearances[0].Price,
+		Appearances:  appearances,
+	}
+
+	assetIDs := map[string]bool{}
+	for i, listing := range appearances {
+		assetIDs[listing.AssetID] = true
+		if listing.Price < provenance.LowestPrice {
+			provenance.LowestPrice = listing.Price
+		}
+		if listing.Price > provenance.HighestPrice {
+			provenance.HighestPrice = listing.Price
+		}
+
+		switch {
+		case listing.RelistedAs != "":
+			provenance.Relists++
+		case listing.Gone != nil:
+			provenance.Sales++
+			if i+1 < len(appearances) {
+				next := appearances[i+1]
+				flip := Flip{
+					Bought:    listing.ListingID,
+					BoughtFor: listing.Price,
+					BoughtAt:  *listing.Gone,
+					Listed:    next.ListingID,
+					ListedFor: next.Price,
+					ListedAt:  next.FirstSeen,
+				}
+				if listing.Price > 0 {
+					flip.Markup = (next.Price - listing.Price) / listing.Price * 100
+				}
+				provenance.Flips = append(provenance.Flips, flip)
+			}
+		}
+	}
+	provenance.Owners = len(assetIDs)
+
+	return &provenance, nil
+}
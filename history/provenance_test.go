@@ -0,0 +1,93 @@
+package history
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"eiffel65/steam"
+)
+
+func TestProvenance(t *testing.T) {
+	db := openTestDB(t)
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	blueGem := func(listingID, assetID, price string) steam.SimpleAsset {
+		asset := listing(listingID, assetID, price, 661, 1)
+		asset.Float.PaintWear = 1046887630
+		return asset
+	}
+	filler := listing("900", "90", "5000.00", 12, 0)
+
+	scans := [][]steam.SimpleAsset{
+		{blueGem("101", "11", "1000.00"), filler},
+		// Relisted higher by the same owner, then bought and relisted higher
+		// still by a flipper.
+		{blueGem("102", "11", "1100.00"), filler},
+		{filler},
+		{blueGem("103", "21", "1650.00"), filler},
+	}
+	for i, scan := range scans {
+		err := db.Record(scan, start.Add(time.Duration(i)*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fingerprint := scans[0][0].Float.Fingerprint()
+	provenance, err := db.Provenance(fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(provenance.Appearances) != 3 || provenance.Appearances[0].ListingID != "101" || provenance.Appearances[2].ListingID != "103" {
+		t.Fatalf("got %+v", provenance.Appearances)
+	}
+	if provenance.Owners != 2 || provenance.Relists != 1 || provenance.Sales != 1 {
+		t.Errorf("got %d owners, %d relists and %d sales", provenance.Owners, provenance.Relists, provenance.Sales)
+	}
+	if provenance.LowestPrice != 1000 || provenance.HighestPrice != 1650 {
+		t.Errorf("got prices %g to %g", provenance.LowestPrice, provenance.HighestPrice)
+	}
+
+	want := Flip{
+		Bought:    "102",
+		BoughtFor: 1100,
+		BoughtAt:  start.Add(2 * time.Hour),
+		Listed:    "103",
+		ListedFor: 1650,
+		ListedAt:  start.Add(3 * time.Hour),
+		Markup:    50,
+	}
+	if len(provenance.Flips) != 1 || provenance.Flips[0] != want {
+		t.Errorf("got flips %+v, want %+v", provenance.Flips, want)
+	}
+
+	// A sale recorded without a price has no markup, rather than an
+	// infinite one that cannot be marshalled.
+	unpriced := listing("201", "31", "0.00", 387, 0)
+	unpriced.Float.PaintWear = 1046887631
+	relisted := listing("202", "41", "800.00", 387, 0)
+	relisted.Float.PaintWear = 1046887631
+	for i, scan := range [][]steam.SimpleAsset{{unpriced}, {}, {relisted}} {
+		err := db.Record(scan, start.Add(time.Duration(10+i)*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	provenance, err = db.Provenance(unpriced.Float.Fingerprint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(provenance.Flips) != 1 || provenance.Flips[0].Markup != 0 {
+		t.Errorf("got flips %+v for an unpriced sale", provenance.Flips)
+	}
+	if _, err := json.Marshal(provenance); err != nil {
+		t.Errorf("failed to marshal provenance: %s", err)
+	}
+
+	_, err = db.Provenance("1-2-3-4")
+	if err == nil {
+		t.Error("expected an error for an item never seen")
+	}
+}
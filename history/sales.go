@@ -0,0 +1,112 @@
+package history
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultFloatBucket is the width of the float ranges sales are grouped in.
+const DefaultFloatBucket float64 = 0.01
+
+// Sale is a listing that most likely sold, at its last price.
+type Sale struct {
+	ListingID  string    `json:"listing_id"`
+	AssetID    string    `json:"asset_id"`
+	FloatValue float64   `json:"float_value"`
+	Price      float64   `json:"price"`
+	Currency   string    `json:"currency"`
+	Sold       time.Time `json:"sold"`
+}
+
+// SalesBucket is the probable sales of one paint seed of an item within a
+// float range, newest first.
+type SalesBucket struct {
+	Name        string  `json:"name"`
+	PaintSeed   int     `json:"paint_seed"`
+	MinFloat    float64 `json:"min_float"`
+	MaxFloat    float64 `json:"max_float"`
+	MedianPrice float64 `json:"median_price"`
+	Sales       []Sale  `json:"sales"`
+}
+
+// salesBucketKey identifies a bucket.
+type salesBucketKey struct {
+	name      string
+	paintSeed int
+	index     int
+}
+
+// SalesLog groups the probable sales matching the query by item, paint seed
+// and float range, with ranges floatBucket wide.
+func (db *DB) SalesLog(query Query, floatBucket float64) ([]SalesBucket, error) {
+	if floatBucket <= 0 || floatBucket > 1 {
+		return nil, fmt.Errorf("invalid float bucket %g, expected more than 0 and at most 1", floatBucket)
+	}
+
+	query.Gone = true
+	listings, err := db.Listings(query)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := map[salesBucketKey]*SalesBucket{}
+	for _, listing := range listings {
+		key := salesBucketKey{
+			name:      listing.Name,
+			paintSeed: listing.PaintSeed,
+			// Nudged so a float on an edge is not put a bucket down by
+			// rounding, e.g. 0.3 / 0.1 = 2.9999999999999996.
+			index: int(math.Floor(listing.FloatValue/floatBucket + 1e-9)),
+		}
+
+		bucket, ok := buckets[key]
+		if !ok {
+			bucket = &SalesBucket{
+				Name:      listing.Name,
+				PaintSeed: listing.PaintSeed,
+				MinFloat:  roundFloat(float64(key.index) * floatBucket),
+				MaxFloat:  roundFloat(float64(key.index+1) * floatBucket),
+			}
+			buckets[key] = bucket
+		}
+
+		bucket.Sales = append(bucket.Sales, Sale{
+			ListingID:  listing.ListingID,
+			AssetID:    listing.AssetID,
+			FloatValue: listing.FloatValue,
+			Price:      listing.Price,
+			Currency:   listing.Currency,
+			Sold:       *listing.Gone,
+		})
+	}
+
+	salesLog := []SalesBucket{}
+	for _, bucket := range buckets {
+		prices := []float64{}
+		for _, sale := range bucket.Sales {
+			prices = append(prices, sale.Price)
+		}
+		sort.Float64s(prices)
+		bucket.MedianPrice = median(prices)
+		salesLog = append(salesLog, *bucket)
+	}
+	sort.Slice(salesLog, func(i, j int) bool {
+		a, b := salesLog[i], salesLog[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.PaintSeed != b.PaintSeed {
+			return a.PaintSeed < b.PaintSeed
+		}
+		return a.MinFloat < b.MinFloat
+	})
+	return salesLog, nil
+}
+
+// roundFloat drops the error left from multiplying out a bucket edge, e.g.
+// 0.15000000000000002.
+func roundFloat(value float64) float64 {
+	return math.Round(value*1e9) / 1e9
+}
@@ -0,0 +1,57 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"eiffel65/steam"
+)
+
+func TestSalesLog(t *testing.T) {
+	db := openTestDB(t)
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	withFloat := func(asset steam.SimpleAsset, floatValue float64) steam.SimpleAsset {
+		asset.Float.FloatValue = floatValue
+		return asset
+	}
+	first := []steam.SimpleAsset{
+		withFloat(listing("101", "11", "100.00", 661, 1), 0.152),
+		withFloat(listing("102", "12", "300.00", 661, 1), 0.158),
+		withFloat(listing("103", "13", "200.00", 661, 1), 0.31),
+		withFloat(listing("104", "14", "50.00", 12, 0), 0.152),
+		withFloat(listing("105", "15", "400.00", 20, 0), 0.2),
+	}
+	// Everything sells apart from 105, and 104 is relisted.
+	second := []steam.SimpleAsset{
+		withFloat(listing("204", "14", "55.00", 12, 0), 0.152),
+		withFloat(listing("105", "15", "400.00", 20, 0), 0.2),
+	}
+	for i, scan := range [][]steam.SimpleAsset{first, second} {
+		err := db.Record(scan, start.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	salesLog, err := db.SalesLog(Query{}, DefaultFloatBucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(salesLog) != 2 {
+		t.Fatalf("got %+v", salesLog)
+	}
+
+	bucket := salesLog[0]
+	if bucket.PaintSeed != 661 || bucket.MinFloat != 0.15 || bucket.MaxFloat != 0.16 || len(bucket.Sales) != 2 || bucket.MedianPrice != 200 {
+		t.Errorf("got %+v", bucket)
+	}
+	if bucket := salesLog[1]; bucket.MinFloat != 0.31 || len(bucket.Sales) != 1 || bucket.Sales[0].ListingID != "103" {
+		t.Errorf("got %+v", bucket)
+	}
+
+	_, err = db.SalesLog(Query{}, 0)
+	if err == nil {
+		t.Error("expected an error for an empty float bucket")
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// runInspect looks up a single item from its inspect link.
+func runInspect(flags *flag.FlagSet, args []string) error {
+	clientFlags := clientOptions{}
+	clientFlags.register(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("please specify one inspect link")
+	}
+
+	settings, err := clientFlags.load()
+	if err != nil {
+		return err
+	}
+
+	steamClient, err := clientFlags.newClient(settings, false)
+	if err != nil {
+		return err
+	}
+
+	asset, err := steamClient.Inspect(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to inspect item: %s", err)
+	}
+
+	return printJSON(asset)
+}
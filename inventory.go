@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// runInventory looks up the public inventory of a Steam profile and
+// highlights its rare patterns, or whatever the rules match.
+func runInventory(flags *flag.FlagSet, args []string) error {
+	clientFlags := clientOptions{}
+	clientFlags.register(flags)
+	alertFlags := alertOptions{}
+	alertFlags.register(flags)
+	outputFlags := outputOptions{}
+	outputFlags.register(flags)
+	profitFlags := profitOptions{}
+	profitFlags.register(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("please specify one SteamID64")
+	}
+
+	settings, err := clientFlags.load()
+	if err != nil {
+		return err
+	}
+
+	rules, err := alertFlags.loadRules(settings)
+	if err != nil {
+		return err
+	}
+
+	printer, err := outputFlags.printer()
+	if err != nil {
+		return err
+	}
+
+	steamClient, err := clientFlags.newClient(settings, false)
+	if err != nil {
+		return err
+	}
+
+	notifier, err := alertFlags.alertSinks(settings, rules)
+	if err != nil {
+		return err
+	}
+
+	assetList, err := steamClient.GetInventory(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to get inventory: %s", err)
+	}
+
+	return reportScan(steamClient, rules, profitFlags, notifier, printer, assetList)
+}
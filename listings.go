@@ -0,0 +1,124 @@
+package main
+
+import (
+	"eiffel65/output"
+	"eiffel65/steam"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// runListings looks up the listings of an item, a family or the watchlist
+// once and prints them along with any highlights.
+func runListings(flags *flag.FlagSet, args []string) error {
+	clientFlags := clientOptions{}
+	clientFlags.register(flags)
+	itemFlags := itemOptions{}
+	itemFlags.register(flags)
+	alertFlags := alertOptions{}
+	alertFlags.register(flags)
+	outputFlags := outputOptions{}
+	outputFlags.register(flags)
+	profitFlags := profitOptions{}
+	profitFlags.register(flags)
+	historyFlags := historyOptions{}
+	historyFlags.register(flags)
+	dryRunPath := flags.String("dry-run", "", "evaluate -rules against the JSON output of an earlier run instead of scanning")
+	flags.Parse(args)
+
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", flags.Args())
+	}
+
+	settings, err := clientFlags.load()
+	if err != nil {
+		return err
+	}
+
+	rules, err := alertFlags.loadRules(settings)
+	if err != nil {
+		return err
+	}
+
+	printer, err := outputFlags.printer()
+	if err != nil {
+		return err
+	}
+
+	if *dryRunPath != "" {
+		return dryRun(rules, printer, *dryRunPath)
+	}
+
+	steamClient, err := clientFlags.newClient(settings, true)
+	if err != nil {
+		return err
+	}
+
+	notifier, err := alertFlags.alertSinks(settings, rules)
+	if err != nil {
+		return err
+	}
+
+	db, err := historyFlags.open(settings)
+	if err != nil {
+		return err
+	}
+	if db != nil {
+		defer db.Close()
+	}
+
+	scan, err := itemFlags.scanner(flags, steamClient, settings)
+	if err != nil {
+		return err
+	}
+
+	assetList, _, err := scan()
+	if err != nil {
+		return fmt.Errorf("failed to get asset listings for %s", err)
+	}
+
+	if assetList == nil || len(*assetList) == 0 {
+		return fmt.Errorf("no results for %s", itemFlags.name)
+	}
+	recordScan(db, *assetList, time.Now())
+
+	return reportScan(steamClient, rules, profitFlags, notifier, printer, assetList)
+}
+
+// dryRun evaluates the rules against listings saved from an earlier run,
+// without touching the network, printing the listings that matched.
+func dryRun(rules []steam.Rule, printer *listingPrinter, dryRunPath string) error {
+	if rules == nil {
+		return fmt.Errorf("please specify the -rules to dry run")
+	}
+
+	file, err := os.Open(dryRunPath)
+	if err != nil {
+		return fmt.Errorf("failed to open saved scan: %s", err)
+	}
+	defer file.Close()
+
+	// Only read the first batch of listings, as a saved watch may hold
+	// several.
+	assetList := []steam.SimpleAsset{}
+	err = json.NewDecoder(file).Decode(&assetList)
+	if err != nil {
+		return fmt.Errorf("failed to read saved scan %s: %s", dryRunPath, err)
+	}
+
+	steam.EstimateProfit(assetList, 0)
+	steam.ScoreDeals(assetList, assetList)
+	matches := steam.EvaluateRules(rules, assetList)
+	slog.Info("dry run", "matches", len(matches), "listings", len(assetList))
+
+	matched := []steam.SimpleAsset{}
+	for _, listing := range output.Listings(assetList, matches) {
+		if listing.Highlighted() {
+			matched = append(matched, listing.SimpleAsset)
+		}
+	}
+	return printer.print(matched, matches)
+}
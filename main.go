@@ -1,71 +1,550 @@
 package main
 
 import (
+	"eiffel65/config"
+	"eiffel65/history"
+	"eiffel65/metrics"
+	"eiffel65/notify"
+	"eiffel65/output"
+	"eiffel65/replay"
 	"eiffel65/steam"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"log"
-)
-
-var (
-	assetName   string
-	steamAPIKey string
-	wearTier    int
-	listings    int
-	statTrak    bool
-	debug       bool
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
 )
 
 const (
-	defaultWearTier     int    = 3
+	defaultWearTier     string = "3"
 	defaultListingCount int    = 25
 	defaultAssetName    string = "AK-47 | Case Hardened"
 )
 
-func init() {
-	flag.StringVar(&assetName, "n", defaultAssetName, "the name of the Steam asset to query")
-	flag.StringVar(&steamAPIKey, "k", "", "the user Steam Web API Key")
-	flag.IntVar(&wearTier, "w", defaultWearTier, "what wear quality to query (1-5 Factory New to Battle-Scarred, default 3)")
-	flag.IntVar(&listings, "l", defaultListingCount, "how many market listings, default 25")
-	flag.BoolVar(&statTrak, "s", false, "whether to query items with StatTrak")
-	flag.BoolVar(&debug, "d", false, "debug mode")
-	flag.Parse()
+// command is a subcommand of the command line, with its own flags.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(flags *flag.FlagSet, args []string) error
+}
+
+// commands lists every subcommand in the order they are shown in the help.
+func commands() []command {
+	return []command{
+		{"listings", "[flags]", "look up the market listings of an item and highlight rare patterns", runListings},
+		{"inspect", "[flags] <inspect link>", "look up the float, pattern and screenshot of a single item", runInspect},
+		{"price", "[flags] <market hash name>", "look up the lowest and median price of an item", runPrice},
+		{"inventory", "[flags] <steamid64>", "look up the public inventory of a profile and highlight rare patterns", runInventory},
+		{"value", "[flags] <steamid64>", "work out what the public inventory of a profile is worth, by item and category", runValue},
+		{"search", "[flags] <query>", "search the market for item names, optionally scanning every result", runSearch},
+		{"watch", "[flags]", "keep rescanning on an interval, reporting only new listings", runWatch},
+		{"rarity", "check [flags] <inspect link>", "check the pattern tier of an item", runRarity},
+		{"history", "[flags]", "look back on the listings recorded in the history database", runHistory},
+		{"serve", "[flags]", "answer lookups over a JSON API and serve the web dashboard", runServe},
+	}
 }
 
 func main() {
-	if steamAPIKey == "" {
-		log.Fatal("please specify an API Key")
+	args := os.Args[1:]
+
+	// Without a subcommand the flags are for listings, as they were before
+	// there were subcommands.
+	name := "listings"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" {
+		usage(os.Stdout)
+		return
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name != name {
+			continue
+		}
+
+		flags := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+		flags.Usage = func() {
+			fmt.Fprintf(flags.Output(), "Usage: eiffel65 %s %s\n\n%s.\n\nFlags:\n", cmd.name, cmd.args, strings.ToUpper(cmd.summary[:1])+cmd.summary[1:])
+			flags.PrintDefaults()
+		}
+
+		err := cmd.run(flags, args)
+		if err != nil {
+			slog.Error(err.Error(), "command", cmd.name)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage(os.Stderr)
+	os.Exit(2)
+}
+
+// usage lists the subcommands.
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: eiffel65 <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun eiffel65 <command> -h for the flags of a command. Without a command the flags are for listings.\n")
+}
+
+// clientOptions are the flags of every command that talks to Steam.
+type clientOptions struct {
+	configPath string
+	apiKey     string
+	debug      bool
+	logLevel   string
+	logFormat  string
+	recordDir  string
+	replayDir  string
+}
+
+// register adds the client flags to a command.
+func (options *clientOptions) register(flags *flag.FlagSet) {
+	flags.StringVar(&options.configPath, "config", "", "a YAML config file, by default $EIFFEL65_CONFIG or "+config.DefaultPath+" if it exists")
+	flags.StringVar(&options.apiKey, "k", "", "the user Steam Web API Key")
+	flags.BoolVar(&options.debug, "d", false, "log debug messages, the same as -log-level debug")
+	flags.StringVar(&options.logLevel, "log-level", "info", "the least level to log: debug, info, warn or error")
+	flags.StringVar(&options.logFormat, "log-format", "text", "how to log: text or json")
+	flags.StringVar(&options.recordDir, "record", "", "save the responses of Steam and the float API as fixtures in this directory")
+	flags.StringVar(&options.replayDir, "replay", "", "answer every request from the fixtures saved with -record in this directory, without going online")
+}
+
+// load sets up the logger, then reads the config file.
+func (options *clientOptions) load() (*config.Config, error) {
+	logger, err := options.logger()
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logger)
+
+	return config.Load(config.Find(options.configPath))
+}
+
+// logger creates the logger chosen by the flags, logging to stderr so the
+// output stays parseable.
+func (options *clientOptions) logger() (*slog.Logger, error) {
+	level := slog.LevelDebug
+	if !options.debug {
+		err := level.UnmarshalText([]byte(options.logLevel))
+		if err != nil {
+			return nil, fmt.Errorf("invalid -log-level %q, expected debug, info, warn or error", options.logLevel)
+		}
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	switch options.logFormat {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, handlerOptions)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, handlerOptions)), nil
+	}
+	return nil, fmt.Errorf("invalid -log-format %q, expected text or json", options.logFormat)
+}
+
+// newClient creates a Steam client from the config, preferring the API key
+// passed on the command line, recording or replaying its requests if asked.
+func (options *clientOptions) newClient(settings *config.Config, needsKey bool) (*steam.Client, error) {
+	if options.recordDir != "" && options.replayDir != "" {
+		return nil, fmt.Errorf("please specify only one of -record and -replay")
+	}
+
+	apiKey := options.apiKey
+	if apiKey == "" {
+		apiKey = settings.SteamAPIKey
+	}
+	if apiKey == "" && needsKey && options.replayDir == "" {
+		return nil, fmt.Errorf("please specify an API Key")
+	}
+	steamClient := settings.NewClient(apiKey)
+
+	switch {
+	case options.recordDir != "":
+		transport, err := replay.NewRecorder(options.recordDir, nil)
+		if err != nil {
+			return nil, err
+		}
+		steamClient.HTTPClient = &http.Client{Transport: transport}
+	case options.replayDir != "":
+		transport, err := replay.NewReplayer(options.replayDir)
+		if err != nil {
+			return nil, err
+		}
+		steamClient.HTTPClient = &http.Client{Transport: transport}
+		// Fixtures are read from disk, so there is nothing to rate limit.
+		steamClient.MarketRateLimit = 0
+		steamClient.FloatRateLimit = 0
+	}
+
+	steamClient.HTTPClient = metrics.Client(steamClient.HTTPClient)
+	return steamClient, nil
+}
+
+// itemOptions are the flags choosing what to scan.
+type itemOptions struct {
+	name       string
+	assetType  string
+	wearTier   string
+	listings   int
+	statTrak   bool
+	bothTrak   bool
+	souvenir   bool
+	familyName string
+}
+
+// register adds the item flags to a command.
+func (options *itemOptions) register(flags *flag.FlagSet) {
+	flags.StringVar(&options.name, "n", defaultAssetName, "the name of the Steam asset to query")
+	flags.StringVar(&options.assetType, "t", "weapon", "the type of item: weapon, knife, gloves, sticker, agent or case")
+	flags.StringVar(&options.wearTier, "w", defaultWearTier, "what wear quality to query (1-5 Factory New to Battle-Scarred, 0 for items without wear, a list like 1,2 or all, default 3)")
+	flags.IntVar(&options.listings, "l", defaultListingCount, "how many market listings, default 25")
+	flags.BoolVar(&options.statTrak, "s", false, "whether to query items with StatTrak")
+	flags.BoolVar(&options.bothTrak, "both", false, "query both StatTrak and non-StatTrak items")
+	flags.BoolVar(&options.souvenir, "souvenir", false, "whether to query Souvenir items")
+	flags.StringVar(&options.familyName, "family", "", "scan every weapon and knife carrying this paint, e.g. \"Case Hardened\"")
+}
+
+// scanner creates the scan chosen by the flags: a whole family, the
+// watchlist in the config when no -n is given, or a single item. The scan
+// also returns the market names it failed to look up.
+func (options *itemOptions) scanner(flags *flag.FlagSet, steamClient *steam.Client, settings *config.Config) (func() (*[]steam.SimpleAsset, []string, error), error) {
+	if !flagSet(flags, "l") && settings.Listings > 0 {
+		options.listings = settings.Listings
+	}
+
+	wearTiers, err := steam.ParseWearTiers(options.wearTier)
+	if err != nil {
+		return nil, err
+	}
+
+	itemType, err := steam.ParseAssetType(options.assetType)
+	if err != nil {
+		return nil, err
+	}
+
+	statTrakOptions := []bool{options.statTrak}
+	if options.bothTrak {
+		statTrakOptions = []bool{false, true}
+	}
+	variants := steam.NewVariants(wearTiers, options.souvenir, statTrakOptions...)
+
+	switch {
+	case options.familyName != "":
+		return countScanned(func() (*[]steam.SimpleAsset, []string, error) {
+			return steamClient.ScanFamily(options.familyName, variants, options.listings)
+		}), nil
+	case !flagSet(flags, "n") && len(settings.Watchlist) > 0:
+		return countScanned(func() (*[]steam.SimpleAsset, []string, error) {
+			return scanWatchlist(steamClient, settings.Watchlist, options.listings)
+		}), nil
+	}
+	return countScanned(func() (*[]steam.SimpleAsset, []string, error) {
+		return steamClient.NewAssetVariants(options.name, itemType, variants, options.listings)
+	}), nil
+}
+
+// countScanned counts the listings of every successful scan in the metrics,
+// whether or not any of them are new.
+func countScanned(scan func() (*[]steam.SimpleAsset, []string, error)) func() (*[]steam.SimpleAsset, []string, error) {
+	return func() (*[]steam.SimpleAsset, []string, error) {
+		assetList, failed, err := scan()
+		if err == nil && assetList != nil {
+			metrics.ListingsScanned(len(*assetList))
+		}
+		return assetList, failed, err
+	}
+}
+
+// alertOptions are the flags choosing what is highlighted and who is alerted.
+type alertOptions struct {
+	rulesPath  string
+	webhookURL string
+	discordURL string
+	slackURL   string
+	smtpAddr   string
+	mailFrom   string
+	mailTo     string
+}
+
+// register adds the alert flags to a command.
+func (options *alertOptions) register(flags *flag.FlagSet) {
+	flags.StringVar(&options.rulesPath, "rules", "", "a JSON file of alert rules to use instead of the built in rare pattern check")
+	flags.StringVar(&options.webhookURL, "webhook", "", "post highlights as JSON to this URL")
+	flags.StringVar(&options.discordURL, "discord", "", "post highlights to this Discord webhook URL")
+	flags.StringVar(&options.slackURL, "slack", "", "post highlights to this Slack webhook URL")
+	flags.StringVar(&options.smtpAddr, "smtp", "", "email highlights through this SMTP server, e.g. localhost:25")
+	flags.StringVar(&options.mailFrom, "mail-from", "", "the address highlight emails are sent from")
+	flags.StringVar(&options.mailTo, "mail-to", "", "comma separated addresses to email highlights to")
+}
+
+// loadRules reads the -rules file, falling back to the rules in the config.
+func (options *alertOptions) loadRules(settings *config.Config) ([]steam.Rule, error) {
+	if options.rulesPath == "" {
+		if len(settings.Rules) > 0 {
+			return settings.Rules, nil
+		}
+		return nil, nil
+	}
+	return steam.LoadRules(options.rulesPath)
+}
+
+// alertSinks creates the notifiers from the config file along with one for
+// each alert sink passed on the command line, which are named after their
+// flag for rules to notify.
+func (options *alertOptions) alertSinks(settings *config.Config, rules []steam.Rule) (*notify.Router, error) {
+	router, err := settings.NewRouter(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := map[string]notify.Config{}
+	if options.webhookURL != "" {
+		configs["webhook"] = notify.Config{Type: "webhook", URL: options.webhookURL}
+	}
+	if options.discordURL != "" {
+		configs["discord"] = notify.Config{Type: "discord", URL: options.discordURL}
+	}
+	if options.slackURL != "" {
+		configs["slack"] = notify.Config{Type: "slack", URL: options.slackURL}
+	}
+	if options.smtpAddr != "" {
+		to := []string{}
+		for _, address := range strings.Split(options.mailTo, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				to = append(to, address)
+			}
+		}
+		configs["smtp"] = notify.Config{Type: "email", SMTPAddr: options.smtpAddr, From: options.mailFrom, To: to}
+	}
+
+	for name, config := range configs {
+		notifier, err := notify.New(config)
+		if err != nil {
+			return nil, err
+		}
+		router.Notifiers[name] = notifier
+	}
+
+	for _, rule := range rules {
+		for _, name := range rule.Notify {
+			if _, ok := router.Notifiers[name]; !ok {
+				return nil, fmt.Errorf("rule %q notifies %q, which is not in the config's notifiers or set by its flag", rule.Name, name)
+			}
+		}
+	}
+
+	return router, nil
+}
+
+// outputOptions are the flags choosing how listings are printed.
+type outputOptions struct {
+	format string
+	sortBy string
+	limit  int
+}
+
+// register adds the output flags to a command.
+func (options *outputOptions) register(flags *flag.FlagSet) {
+	flags.StringVar(&options.format, "o", output.JSON, "the output format: json, table, csv, ndjson or template=<text/template>, e.g. template='{{.Name}} {{.Float.PaintSeed}}'")
+	flags.StringVar(&options.sortBy, "sort", "", "sort listings by price, float, seed, tier or deal, adding :desc to reverse, e.g. float:desc")
+	flags.IntVar(&options.limit, "limit", 0, "print at most this many listings after sorting, 0 for all")
+}
+
+// printer creates a printer for the chosen format, sort and limit.
+func (options *outputOptions) printer() (*listingPrinter, error) {
+	printer, err := output.NewPrinter(os.Stdout, options.format)
+	if err != nil {
+		return nil, err
+	}
+
+	listingPrinter := &listingPrinter{Printer: printer, limit: options.limit}
+	if options.sortBy != "" {
+		sortBy, err := steam.ParseSort(options.sortBy)
+		if err != nil {
+			return nil, err
+		}
+		listingPrinter.sortBy = &sortBy
+	}
+	return listingPrinter, nil
+}
+
+// listingPrinter sorts and limits listings before printing them.
+type listingPrinter struct {
+	*output.Printer
+	sortBy *steam.Sort
+	limit  int
+}
+
+// print prints the listings along with the rules they matched.
+func (printer *listingPrinter) print(assetList []steam.SimpleAsset, matches []steam.RuleMatch) error {
+	assetList = append([]steam.SimpleAsset{}, assetList...)
+	if printer.sortBy != nil {
+		steam.SortAssets(assetList, *printer.sortBy)
+	}
+	if printer.limit > 0 && len(assetList) > printer.limit {
+		assetList = assetList[:printer.limit]
 	}
+	return printer.Print(output.Listings(assetList, matches))
+}
+
+// profitOptions are the flags for estimating the profit of relisting.
+type profitOptions struct {
+	estimate    bool
+	targetPrice float64
+}
+
+// register adds the profit flags to a command.
+func (options *profitOptions) register(flags *flag.FlagSet) {
+	flags.BoolVar(&options.estimate, "profit", false, "price each listing and estimate the profit after fees of relisting it at the market median")
+	flags.Float64Var(&options.targetPrice, "target", 0, "also estimate the profit after fees of relisting at this price, e.g. 120.50")
+}
+
+// historyOptions are the flags for recording listings in the history
+// database.
+type historyOptions struct {
+	path string
+}
+
+// register adds the history flags to a command.
+func (options *historyOptions) register(flags *flag.FlagSet) {
+	flags.StringVar(&options.path, "history", "", "a SQLite database to record every listing scanned in, by default the history in the config")
+}
 
-	if wearTier > 5 || wearTier < 1 {
-		log.Fatal("please specify a wear tear between 1 and 5")
+// open opens the history database, or returns nil if there is none.
+func (options *historyOptions) open(settings *config.Config) (*history.DB, error) {
+	path := options.path
+	if path == "" {
+		path = settings.History
 	}
+	if path == "" {
+		return nil, nil
+	}
+	return history.Open(path)
+}
 
-	steamClient := steam.NewClient(steamAPIKey)
+// flagSet reports whether a flag was passed on the command line.
+func flagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
 
-	assetList, err := steamClient.NewAsset(assetName, wearTier, listings, statTrak, debug)
+// printJSON prints a value as indented JSON.
+func printJSON(value interface{}) error {
+	valueJSON, err := json.MarshalIndent(value, "", "\t")
 	if err != nil {
-		log.Fatalf("failed to get asset listings for %s", err)
+		return fmt.Errorf("failed to marshal JSON: %s", err)
+	}
+	fmt.Printf("%s\n", valueJSON)
+	return nil
+}
+
+// reportScan prints the listings of a one off scan with their highlights,
+// alerting about each of them.
+func reportScan(steamClient *steam.Client, rules []steam.Rule, profit profitOptions, notifier notify.Notifier, printer *listingPrinter, assetList *[]steam.SimpleAsset) error {
+	matches := findMatches(steamClient, rules, profit, *assetList, *assetList)
+	sendAlerts(notifier, matches)
+
+	return printer.print(*assetList, matches)
+}
+
+// findMatches scores the listings as deals against the pool they were found
+// in, estimates their profit and checks them against the rules, pricing them
+// first if the rules or profit estimate need it. Without any rules it falls
+// back to CheckForRarity and CheckForDeals.
+func findMatches(steamClient *steam.Client, rules []steam.Rule, profit profitOptions, assetList, pool []steam.SimpleAsset) []steam.RuleMatch {
+	if profit.estimate || steam.NeedsMarketValue(rules) {
+		steamClient.PriceAssets(assetList)
+	}
+	if profit.estimate || profit.targetPrice > 0 || steam.NeedsMarketValue(rules) {
+		steam.EstimateProfit(assetList, profit.targetPrice)
 	}
 
-	if assetList == nil {
-		log.Fatalf("no results for %s", assetName)
+	if rules == nil {
+		steam.ScoreDeals(assetList, pool)
+
+		matches := []steam.RuleMatch{}
+		for _, asset := range steam.RankHighlights(steam.CheckForRarity(assetList)) {
+			matches = append(matches, steam.RuleMatch{Rule: "rare pattern", Asset: asset})
+		}
+		for _, asset := range steam.CheckForDeals(assetList) {
+			matches = append(matches, steam.RuleMatch{Rule: "below comparable floats", Asset: asset})
+		}
+		return matches
 	}
 
-	assetJSON, err := json.MarshalIndent(assetList, "", "\t")
+	if steam.NeedsStickerValue(rules) {
+		steamClient.PriceStickers(assetList)
+	}
+	steam.ScoreDeals(assetList, pool)
+	return steam.EvaluateRules(rules, assetList)
+}
+
+// recordScan stores the listings of a scan in the history database, if there
+// is one.
+func recordScan(db *history.DB, assetList []steam.SimpleAsset, seen time.Time) {
+	if db == nil {
+		return
+	}
+	err := db.Record(assetList, seen)
 	if err != nil {
-		log.Fatalf("failed to marshal listing JSON: %s", err)
+		slog.Warn("failed to record listings in the history", "error", err)
 	}
+}
 
-	notableIDs := steam.CheckForRarity(*assetList)
-	highlight := ""
-	if len(notableIDs) > 0 {
-		for id, asset := range notableIDs {
-			highlight += fmt.Sprintf("\nHIGHLIGHT: %s SEED: %d FLOAT: %.4f PRICE: %s%s SCREENSHOT: %s",
-				id, asset.Float.PaintSeed, asset.Float.FloatValue, asset.ListingTotalPrice, asset.ListingCurrency, asset.ScreenshotURL)
+// sendAlerts notifies every alert sink about each match.
+func sendAlerts(notifier notify.Notifier, matches []steam.RuleMatch) {
+	for _, match := range matches {
+		metrics.AlertFired(match.Rule)
+		err := notifier.Notify(notify.NewAlert(match.Rule, match.Asset))
+		if err != nil {
+			slog.Warn("failed to send alert", "listing_id", match.Asset.ListingID, "rule", match.Rule, "error", err)
 		}
 	}
+}
 
-	fmt.Printf("%s\n\n%s", assetJSON, highlight)
+// scanWatchlist fetches the listings of every item on the watchlist into one
+// list, keeping only those that pass the item's filter. Items that fail are
+// logged and skipped, and the market names that failed are returned as for
+// NewAssetVariants.
+func scanWatchlist(steamClient *steam.Client, watchlist []config.WatchItem, listings int) (*[]steam.SimpleAsset, []string, error) {
+	assetList := []steam.SimpleAsset{}
+	failed := []string{}
+	for _, item := range watchlist {
+		itemType, variants, err := item.Query()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		itemListings := listings
+		if item.Listings > 0 {
+			itemListings = item.Listings
+		}
+
+		assets, itemFailed, err := steamClient.NewAssetVariants(item.Name, itemType, variants, itemListings)
+		failed = append(failed, itemFailed...)
+		if err != nil {
+			slog.Warn("failed to get asset listings", "name", item.Name, "error", err)
+			continue
+		}
+		for _, asset := range *assets {
+			if item.Filter.Match(asset) {
+				assetList = append(assetList, asset)
+			}
+		}
+	}
+	return &assetList, failed, nil
 }
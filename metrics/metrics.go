@@ -0,0 +1,168 @@
+// Package metrics exposes the health of the scanner in the Prometheus text
+// format: requests made to each upstream, how they were rate limited, cache
+// hits, listings scanned and alerts fired.
+package metrics
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// The upstreams requests are counted by.
+const (
+	UpstreamMarket        string = "steam_market"
+	UpstreamMarketSearch  string = "steam_market_search"
+	UpstreamInventory     string = "steam_inventory"
+	UpstreamPriceOverview string = "price_overview"
+	UpstreamSteamAPI      string = "steam_api"
+	UpstreamFloat         string = "csgofloat"
+	UpstreamScreenshot    string = "screenshot"
+)
+
+const screenshotHost string = "files.opskins.media"
+
+var (
+	registry = prometheus.NewRegistry()
+
+	upstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "eiffel65_upstream_requests_total",
+		Help: "Requests made to each upstream, by status code, or \"error\" if there was no response.",
+	}, []string{"upstream", "code"})
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "eiffel65_upstream_request_duration_seconds",
+		Help:    "How long requests to each upstream took, including the rate limited ones.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"upstream"})
+	upstreamRateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "eiffel65_upstream_rate_limited_total",
+		Help: "Requests to each upstream answered with 429 Too Many Requests.",
+	}, []string{"upstream"})
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "eiffel65_cache_lookups_total",
+		Help: "Lookups of the serve cache, by whether they were a hit or a miss.",
+	}, []string{"result"})
+	listingsScanned = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "eiffel65_listings_scanned_total",
+		Help: "Market listings scanned.",
+	})
+	alertsFired = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "eiffel65_alerts_fired_total",
+		Help: "Alerts fired, by the rule that matched.",
+	}, []string{"rule"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		upstreamRequests,
+		upstreamDuration,
+		upstreamRateLimited,
+		cacheLookups,
+		listingsScanned,
+		alertsFired,
+	)
+}
+
+// Handler serves the metrics for Prometheus to scrape.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Client wraps an HTTP client so every request it makes is counted and timed
+// by upstream. A nil client wraps http.DefaultClient.
+func Client(httpClient *http.Client) *http.Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	instrumented := *httpClient
+	instrumented.Transport = Transport(httpClient.Transport)
+	return &instrumented
+}
+
+// Transport wraps a round tripper so every request it makes is counted and
+// timed by upstream. A nil round tripper wraps http.DefaultTransport.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return transport{base: base}
+}
+
+// transport counts and times the requests of the round tripper it wraps.
+type transport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip makes the request, recording it against its upstream.
+func (t transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	upstream := Upstream(r.URL)
+	start := time.Now()
+	response, err := t.base.RoundTrip(r)
+	upstreamDuration.WithLabelValues(upstream).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		upstreamRequests.WithLabelValues(upstream, "error").Inc()
+		return response, err
+	}
+	upstreamRequests.WithLabelValues(upstream, strconv.Itoa(response.StatusCode)).Inc()
+	if response.StatusCode == http.StatusTooManyRequests {
+		upstreamRateLimited.WithLabelValues(upstream).Inc()
+	}
+	return response, nil
+}
+
+// Upstream names the upstream a request goes to from its URL, going by the
+// path rather than the host so providers configured elsewhere are still told
+// apart, and the float API by the inspect link it is passed. Requests it does
+// not know are named by their host.
+func Upstream(requestURL *url.URL) string {
+	path := requestURL.Path
+	// The query is not parsed, as inspect links carry the market's
+	// %listingid% placeholder, which is not a valid escape.
+	inspectURL := strings.Contains(requestURL.RawQuery, "url=steam:") || strings.Contains(requestURL.RawQuery, "url=steam%3A")
+
+	switch {
+	case strings.Contains(path, "/market/listings/"):
+		return UpstreamMarket
+	case strings.Contains(path, "/market/search"):
+		return UpstreamMarketSearch
+	case strings.Contains(path, "/inventory/"):
+		return UpstreamInventory
+	case strings.Contains(path, "/market/priceoverview"):
+		return UpstreamPriceOverview
+	case strings.Contains(path, "/ISteamEconomy/"):
+		return UpstreamSteamAPI
+	case inspectURL:
+		return UpstreamFloat
+	case requestURL.Host == screenshotHost:
+		return UpstreamScreenshot
+	}
+	return requestURL.Host
+}
+
+// CacheLookup counts a lookup of the serve cache.
+func CacheLookup(hit bool) {
+	if hit {
+		cacheLookups.WithLabelValues("hit").Inc()
+		return
+	}
+	cacheLookups.WithLabelValues("miss").Inc()
+}
+
+// ListingsScanned counts the listings of a scan.
+func ListingsScanned(count int) {
+	listingsScanned.Add(float64(count))
+}
+
+// AlertFired counts an alert for the rule.
+func AlertFired(rule string) {
+	alertsFired.WithLabelValues(rule).Inc()
+}
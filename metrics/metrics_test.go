@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestUpstream(t *testing.T) {
+	tests := []struct {
+		rawURL string
+		want   string
+	}{
+		{"https://steamcommunity.com/market/listings/730/AK-47%20%7C%20Redline%20%28Field-Tested%29/render?start=0", UpstreamMarket},
+		{"https://steamcommunity.com/market/search/render?query=Redline", UpstreamMarketSearch},
+		{"https://steamcommunity.com/inventory/76561198299749713/730/2?l=english&count=2000", UpstreamInventory},
+		{"https://steamcommunity.com/market/priceoverview?appid=730", UpstreamPriceOverview},
+		{"https://api.steampowered.com/ISteamEconomy/GetAssetClassInfo/v0001?classid0=1", UpstreamSteamAPI},
+		{"https://api.csgofloat.com/?url=steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20S1A2D3", UpstreamFloat},
+		{"https://api.csgofloat.com/?url=steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20M%listingid%A2D3", UpstreamFloat},
+		{"https://files.opskins.media/file/opskins-patternindex/7_44_661.jpg", UpstreamScreenshot},
+		{"http://localhost:8080/proxy/market/listings/730/AK-47/render", UpstreamMarket},
+		{"https://example.com/other", "example.com"},
+	}
+
+	for _, test := range tests {
+		requestURL, err := url.Parse(test.rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Upstream(requestURL); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.rawURL, got, test.want)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/market/priceoverview") {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer upstream.Close()
+
+	httpClient := Client(upstream.Client())
+	for _, path := range []string{"/market/listings/730/AK-47/render", "/market/listings/730/AK-47/render", "/market/priceoverview"} {
+		response, err := httpClient.Get(upstream.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+	}
+	CacheLookup(true)
+	CacheLookup(false)
+	CacheLookup(false)
+	ListingsScanned(25)
+	AlertFired("rare pattern")
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+
+	for _, want := range []string{
+		`eiffel65_upstream_requests_total{code="200",upstream="steam_market"} 2`,
+		`eiffel65_upstream_requests_total{code="429",upstream="price_overview"} 1`,
+		`eiffel65_upstream_rate_limited_total{upstream="price_overview"} 1`,
+		`eiffel65_upstream_request_duration_seconds_count{upstream="steam_market"} 2`,
+		`eiffel65_cache_lookups_total{result="hit"} 1`,
+		`eiffel65_cache_lookups_total{result="miss"} 2`,
+		`eiffel65_listings_scanned_total 25`,
+		`eiffel65_alerts_fired_total{rule="rare pattern"} 1`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("missing %s in:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Package notify sends alerts about notable market listings to webhooks,
+// chat services and email.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"eiffel65/steam"
+)
+
+const (
+	webhookNotifier string = "webhook"
+	discordNotifier string = "discord"
+	slackNotifier   string = "slack"
+	emailNotifier   string = "email"
+	requestTimeout         = 10 * time.Second
+)
+
+// Alert is a notable listing, with everything needed to judge it at a glance.
+type Alert struct {
+	Rule          string  `json:"rule,omitempty"`
+	Name          string  `json:"name"`
+	ListingID     string  `json:"listing_id"`
+	PaintSeed     int     `json:"paint_seed"`
+	FloatValue    float64 `json:"float_value"`
+	Price         string  `json:"price"`
+	Currency      string  `json:"currency"`
+	InspectURL    string  `json:"inspect_url,omitempty"`
+	ScreenshotURL string  `json:"screenshot_url,omitempty"`
+	DealScore     float64 `json:"deal_score,omitempty"`
+}
+
+// Notifier sends alerts somewhere.
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// Config describes an alert sink. URL is used by the webhook, discord and
+// slack types and the SMTP fields by the email type.
+type Config struct {
+	Type     string   `json:"type" yaml:"type"`
+	URL      string   `json:"url,omitempty" yaml:"url,omitempty"`
+	SMTPAddr string   `json:"smtp_addr,omitempty" yaml:"smtp_addr,omitempty"`
+	Username string   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string   `json:"password,omitempty" yaml:"password,omitempty"`
+	From     string   `json:"from,omitempty" yaml:"from,omitempty"`
+	To       []string `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// New creates the notifier described by the config.
+func New(config Config) (Notifier, error) {
+	switch strings.ToLower(config.Type) {
+	case webhookNotifier:
+		if config.URL == "" {
+			return nil, errors.New("webhook notifier needs a url")
+		}
+		return &Webhook{URL: config.URL}, nil
+	case discordNotifier:
+		if config.URL == "" {
+			return nil, errors.New("discord notifier needs a webhook url")
+		}
+		return &Discord{WebhookURL: config.URL}, nil
+	case slackNotifier:
+		if config.URL == "" {
+			return nil, errors.New("slack notifier needs a webhook url")
+		}
+		return &Slack{WebhookURL: config.URL}, nil
+	case emailNotifier:
+		if config.SMTPAddr == "" || config.From == "" || len(config.To) == 0 {
+			return nil, errors.New("email notifier needs an smtp_addr, from and to")
+		}
+		return &Email{
+			Addr:     config.SMTPAddr,
+			Username: config.Username,
+			Password: config.Password,
+			From:     config.From,
+			To:       config.To,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown notifier type %q, expected webhook, discord, slack or email", config.Type)
+}
+
+// NewAlert creates an alert for a listing, naming the rule that matched it.
+func NewAlert(rule string, asset steam.SimpleAsset) Alert {
+	alert := Alert{
+		Rule:          rule,
+		Name:          asset.Name,
+		ListingID:     asset.ListingID,
+		PaintSeed:     asset.Float.PaintSeed,
+		FloatValue:    asset.Float.FloatValue,
+		Price:         asset.ListingTotalPrice,
+		Currency:      steam.CurrencyName(asset.ListingCurrency),
+		InspectURL:    asset.InspectURL,
+		ScreenshotURL: asset.ScreenshotURL,
+	}
+	if asset.Deal != nil {
+		alert.DealScore = asset.Deal.Score
+	}
+	return alert
+}
+
+// Multi sends each alert to several notifiers.
+type Multi []Notifier
+
+// Notify sends the alert to every notifier, returning the first error once
+// all have been tried.
+func (multi Multi) Notify(alert Alert) error {
+	var firstErr error
+	for _, notifier := range multi {
+		err := notifier.Notify(alert)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Router sends each alert to the notifiers its rule names in Routes, or to
+// every notifier when the rule names none.
+type Router struct {
+	Notifiers map[string]Notifier
+	Routes    map[string][]string
+}
+
+// Notify sends the alert to the notifiers routed for its rule, returning the
+// first error once all have been tried.
+func (router *Router) Notify(alert Alert) error {
+	names, ok := router.Routes[alert.Rule]
+	if !ok || len(names) == 0 {
+		names = make([]string, 0, len(router.Notifiers))
+		for name := range router.Notifiers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	multi := Multi{}
+	for _, name := range names {
+		notifier, ok := router.Notifiers[name]
+		if !ok {
+			return fmt.Errorf("rule %q routes to unknown notifier %q", alert.Rule, name)
+		}
+		multi = append(multi, notifier)
+	}
+	return multi.Notify(alert)
+}
+
+// Summary is a one line description of the alert.
+func (alert Alert) Summary() string {
+	summary := fmt.Sprintf("%s SEED: %d FLOAT: %.4f PRICE: %s %s", alert.Name, alert.PaintSeed, alert.FloatValue, alert.Price, alert.Currency)
+	if alert.Rule != "" {
+		summary = "[" + alert.Rule + "] " + summary
+	}
+	return summary
+}
+
+// postJSON posts a JSON payload, treating any non-2xx status as an error.
+func postJSON(httpClient *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: requestTimeout}
+	}
+
+	response, err := httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("HTTP: %d , alert was not accepted", response.StatusCode)
+	}
+	return nil
+}
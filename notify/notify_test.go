@@ -0,0 +1,326 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"eiffel65/float"
+	"eiffel65/steam"
+)
+
+var testAlert = Alert{
+	Rule:          "blue gems",
+	Name:          "AK-47 | Case Hardened (Field-Tested)",
+	ListingID:     "3141",
+	PaintSeed:     661,
+	FloatValue:    0.2113,
+	Price:         "1024.00",
+	Currency:      "GBP",
+	InspectURL:    "steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20M1A2D3",
+	ScreenshotURL: "https://files.opskins.media/file/opskins-patternindex/7_44_661.jpg",
+}
+
+func TestNewAlert(t *testing.T) {
+	asset := steam.SimpleAsset{
+		Name:              "AK-47 | Case Hardened (Field-Tested)",
+		ListingID:         "3141",
+		ListingCurrency:   "2",
+		ListingTotalPrice: "1024.00",
+		Float:             float.AssetFloat{PaintSeed: 661, FloatValue: 0.2113},
+	}
+
+	alert := NewAlert("blue gems", asset)
+	if alert.Currency != "GBP" {
+		t.Errorf("got currency %q, want Steam's number named", alert.Currency)
+	}
+	want := "[blue gems] AK-47 | Case Hardened (Field-Tested) SEED: 661 FLOAT: 0.2113 PRICE: 1024.00 GBP"
+	if summary := alert.Summary(); summary != want {
+		t.Errorf("got summary %q, want %q", summary, want)
+	}
+}
+
+// receiveJSON starts a server that decodes one posted JSON body into payload.
+func receiveJSON(t *testing.T, status int, payload interface{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s with content type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		err := json.NewDecoder(r.Body).Decode(payload)
+		if err != nil {
+			t.Errorf("failed to decode payload: %s", err)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebhook(t *testing.T) {
+	received := Alert{}
+	server := receiveJSON(t, http.StatusOK, &received)
+
+	notifier, err := New(Config{Type: "webhook", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notifier.Notify(testAlert)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if received != testAlert {
+		t.Errorf("got %+v, want %+v", received, testAlert)
+	}
+}
+
+func TestWebhookRejected(t *testing.T) {
+	server := receiveJSON(t, http.StatusBadRequest, &Alert{})
+
+	err := (&Webhook{URL: server.URL}).Notify(testAlert)
+	if err == nil {
+		t.Error("expected an error for a rejected alert")
+	}
+}
+
+func TestDiscord(t *testing.T) {
+	received := discordPayload{}
+	server := receiveJSON(t, http.StatusNoContent, &received)
+
+	notifier, err := New(Config{Type: "discord", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notifier.Notify(testAlert)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.Contains(received.Content, "[blue gems]") || len(received.Embeds) != 1 {
+		t.Fatalf("got %+v", received)
+	}
+	embed := received.Embeds[0]
+	if embed.Title != testAlert.Name || embed.Image == nil || embed.Image.URL != testAlert.ScreenshotURL {
+		t.Errorf("got embed %+v", embed)
+	}
+	if embed.Fields[0].Value != "661" || embed.Fields[2].Value != "1024.00 GBP" {
+		t.Errorf("got fields %+v", embed.Fields)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	received := slackPayload{}
+	server := receiveJSON(t, http.StatusOK, &received)
+
+	notifier, err := New(Config{Type: "slack", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notifier.Notify(testAlert)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(received.Blocks) != 2 {
+		t.Fatalf("got %d blocks, want a section and an image", len(received.Blocks))
+	}
+	if !strings.Contains(received.Blocks[0].Text.Text, "Seed: 661") || !strings.Contains(received.Blocks[0].Text.Text, "Price: 1024.00 GBP") || !strings.Contains(received.Blocks[0].Text.Text, testAlert.InspectURL) {
+		t.Errorf("got section %q", received.Blocks[0].Text.Text)
+	}
+	if received.Blocks[1].ImageURL != testAlert.ScreenshotURL {
+		t.Errorf("got image %q", received.Blocks[1].ImageURL)
+	}
+}
+
+// fakeSMTP accepts messages one connection at a time and sends their data
+// down the channel.
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			serveSMTP(conn, messages)
+		}
+	}()
+
+	return listener.Addr().String(), messages
+}
+
+// serveSMTP speaks enough SMTP on a connection to receive messages, sending
+// each one to messages.
+func serveSMTP(conn net.Conn, messages chan<- string) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ready")
+
+	data := strings.Builder{}
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if inData {
+			if line == ".\r\n" {
+				inData = false
+				messages <- data.String()
+				reply("250 queued")
+				continue
+			}
+			data.WriteString(line)
+			continue
+		}
+
+		switch command := strings.ToUpper(strings.Fields(line)[0]); command {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL", "RCPT":
+			reply("250 ok")
+		case "DATA":
+			inData = true
+			reply("354 send data")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestEmail(t *testing.T) {
+	addr, messages := fakeSMTP(t)
+
+	notifier, err := New(Config{Type: "email", SMTPAddr: addr, From: "scanner@example.com", To: []string{"team@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = notifier.Notify(testAlert)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	message := <-messages
+	for _, want := range []string{
+		"To: team@example.com",
+		"Subject: eiffel65: " + testAlert.Name,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"Seed: 661",
+		"Price: 1024.00 GBP",
+		"Screenshot: " + testAlert.ScreenshotURL,
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("message is missing %q:\n%s", want, message)
+		}
+	}
+
+	// A subject that is not ASCII is encoded.
+	knife := testAlert
+	knife.Name = "★ StatTrak™ Karambit | Case Hardened (Field-Tested)"
+	err = notifier.Notify(knife)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	message = <-messages
+	subject := ""
+	for _, line := range strings.Split(message, "\r\n") {
+		if strings.HasPrefix(line, "Subject: ") {
+			subject = strings.TrimPrefix(line, "Subject: ")
+		}
+	}
+	if !strings.HasPrefix(subject, "=?utf-8?q?") {
+		t.Errorf("got subject %q, want it Q-encoded", subject)
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(subject)
+	if err != nil || decoded != "eiffel65: "+knife.Name {
+		t.Errorf("got subject %q decoded as %q, %v", subject, decoded, err)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, config := range []Config{
+		{Type: "pager"},
+		{Type: "webhook"},
+		{Type: "discord"},
+		{Type: "slack"},
+		{Type: "email", SMTPAddr: "localhost:25"},
+	} {
+		if _, err := New(config); err == nil {
+			t.Errorf("%+v: expected an error", config)
+		}
+	}
+}
+
+func TestMulti(t *testing.T) {
+	first, second := Alert{}, Alert{}
+	failing := receiveJSON(t, http.StatusInternalServerError, &first)
+	working := receiveJSON(t, http.StatusOK, &second)
+
+	err := Multi{&Webhook{URL: failing.URL}, &Webhook{URL: working.URL}}.Notify(testAlert)
+	if err == nil {
+		t.Error("expected the failing webhook's error")
+	}
+	if second != testAlert {
+		t.Error("expected the second webhook to still be notified")
+	}
+}
+
+// recorder keeps every alert it is sent.
+type recorder struct {
+	alerts []Alert
+}
+
+func (r *recorder) Notify(alert Alert) error {
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func TestRouter(t *testing.T) {
+	team, personal := &recorder{}, &recorder{}
+	router := &Router{
+		Notifiers: map[string]Notifier{"team": team, "personal": personal},
+		Routes:    map[string][]string{"blue gems": {"personal"}, "missing": {"pager"}},
+	}
+
+	err := router.Notify(testAlert)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(personal.alerts) != 1 || len(team.alerts) != 0 {
+		t.Errorf("routed alert went to team %d and personal %d times", len(team.alerts), len(personal.alerts))
+	}
+
+	unrouted := testAlert
+	unrouted.Rule = "low float"
+	err = router.Notify(unrouted)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(personal.alerts) != 2 || len(team.alerts) != 1 {
+		t.Errorf("unrouted alert should go to every notifier")
+	}
+
+	missing := testAlert
+	missing.Rule = "missing"
+	if router.Notify(missing) == nil {
+		t.Error("expected an error for an unknown notifier")
+	}
+}
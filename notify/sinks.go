@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strings"
+)
+
+// Webhook posts each alert as JSON to a URL.
+type Webhook struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// Notify posts the alert.
+func (webhook *Webhook) Notify(alert Alert) error {
+	return postJSON(webhook.HTTPClient, webhook.URL, alert)
+}
+
+// Discord posts each alert to a Discord incoming webhook as an embed with the
+// screenshot attached.
+type Discord struct {
+	WebhookURL string
+	HTTPClient *http.Client
+}
+
+// discordPayload is the body of a Discord webhook message.
+type discordPayload struct {
+	Content string         `json:"content"`
+	Embeds  []discordEmbed `json:"embeds,omitempty"`
+}
+
+// discordEmbed is a rich card in a Discord message.
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+	Image       *discordEmbedImage  `json:"image,omitempty"`
+}
+
+// discordEmbedField is a name and value shown in an embed.
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// discordEmbedImage is an image shown in an embed.
+type discordEmbedImage struct {
+	URL string `json:"url"`
+}
+
+// Notify posts the alert.
+func (discord *Discord) Notify(alert Alert) error {
+	embed := discordEmbed{
+		Title:       alert.Name,
+		Description: alert.InspectURL,
+		Fields: []discordEmbedField{
+			{Name: "Seed", Value: fmt.Sprintf("%d", alert.PaintSeed), Inline: true},
+			{Name: "Float", Value: fmt.Sprintf("%.6f", alert.FloatValue), Inline: true},
+			{Name: "Price", Value: alert.Price + " " + alert.Currency, Inline: true},
+		},
+	}
+	if alert.ScreenshotURL != "" {
+		embed.Image = &discordEmbedImage{URL: alert.ScreenshotURL}
+	}
+
+	return postJSON(discord.HTTPClient, discord.WebhookURL, discordPayload{
+		Content: alert.Summary(),
+		Embeds:  []discordEmbed{embed},
+	})
+}
+
+// Slack posts each alert to a Slack incoming webhook.
+type Slack struct {
+	WebhookURL string
+	HTTPClient *http.Client
+}
+
+// slackPayload is the body of a Slack webhook message.
+type slackPayload struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks,omitempty"`
+}
+
+// slackBlock is a section or image block in a Slack message.
+type slackBlock struct {
+	Type     string     `json:"type"`
+	Text     *slackText `json:"text,omitempty"`
+	ImageURL string     `json:"image_url,omitempty"`
+	AltText  string     `json:"alt_text,omitempty"`
+}
+
+// slackText is formatted text in a Slack block.
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Notify posts the alert.
+func (slack *Slack) Notify(alert Alert) error {
+	text := fmt.Sprintf("*%s*\nSeed: %d  Float: %.6f  Price: %s %s", alert.Name, alert.PaintSeed, alert.FloatValue, alert.Price, alert.Currency)
+	if alert.Rule != "" {
+		text = fmt.Sprintf("Rule: %s\n%s", alert.Rule, text)
+	}
+	if alert.InspectURL != "" {
+		text += "\n" + alert.InspectURL
+	}
+
+	blocks := []slackBlock{{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}}}
+	if alert.ScreenshotURL != "" {
+		blocks = append(blocks, slackBlock{Type: "image", ImageURL: alert.ScreenshotURL, AltText: alert.Name})
+	}
+
+	return postJSON(slack.HTTPClient, slack.WebhookURL, slackPayload{
+		Text:   alert.Summary(),
+		Blocks: blocks,
+	})
+}
+
+// Email sends each alert as a plain text email over SMTP, authenticating
+// when a username is set.
+type Email struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Notify sends the alert.
+func (email *Email) Notify(alert Alert) error {
+	var auth smtp.Auth
+	if email.Username != "" {
+		host := email.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", email.Username, email.Password, host)
+	}
+
+	body := strings.Builder{}
+	fmt.Fprintf(&body, "From: %s\r\n", email.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(email.To, ", "))
+	// Names such as "★ Karambit" and "StatTrak™" are not ASCII, so the
+	// subject is encoded as headers must be.
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "eiffel65: "+alert.Name))
+	fmt.Fprintf(&body, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&body, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	if alert.Rule != "" {
+		fmt.Fprintf(&body, "Rule: %s\r\n", alert.Rule)
+	}
+	fmt.Fprintf(&body, "Listing: %s\r\n", alert.ListingID)
+	fmt.Fprintf(&body, "Seed: %d\r\n", alert.PaintSeed)
+	fmt.Fprintf(&body, "Float: %.6f\r\n", alert.FloatValue)
+	fmt.Fprintf(&body, "Price: %s %s\r\n", alert.Price, alert.Currency)
+	fmt.Fprintf(&body, "Inspect: %s\r\n", alert.InspectURL)
+	fmt.Fprintf(&body, "Screenshot: %s\r\n", alert.ScreenshotURL)
+
+	return smtp.SendMail(email.Addr, auth, email.From, email.To, []byte(body.String()))
+}
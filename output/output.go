@@ -0,0 +1,253 @@
+// Package output prints listings as JSON, an aligned table, CSV, NDJSON or
+// through a user supplied template.
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"eiffel65/steam"
+)
+
+const (
+	// JSON prints each batch of listings as an indented JSON array.
+	JSON string = "json"
+	// Table prints aligned columns for reading in a terminal.
+	Table string = "table"
+	// CSV prints comma separated values with a header row, for spreadsheets.
+	CSV string = "csv"
+	// NDJSON prints a JSON object per line, for piping into jq.
+	NDJSON string = "ndjson"
+	// templatePrefix starts a format that executes a text/template for each
+	// listing, e.g. "template={{.Name}} {{.Float.PaintSeed}}".
+	templatePrefix string = "template="
+)
+
+// csvHeader names the CSV columns.
+var csvHeader = []string{
+	"listing_id", "id", "name", "variant", "wear", "paint_seed", "float",
+	"price", "fee", "total_price", "currency", "rarity_tier", "deal_score",
+	"comparable_discount", "median_profit", "target_profit", "highlights",
+	"inspect_url", "screenshot_url", "fingerprint",
+}
+
+// Listing is a listing as printed, with the names of the rules it matched
+// and the fingerprint to look the item up in the history by.
+type Listing struct {
+	steam.SimpleAsset
+	Highlights  []string `json:"highlights,omitempty"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+}
+
+// Highlighted reports whether the listing matched any rule.
+func (listing Listing) Highlighted() bool {
+	return len(listing.Highlights) > 0
+}
+
+// Key identifies the listing, falling back to the asset ID for items that are
+// not listed.
+func (listing Listing) Key() string {
+	return steam.ListingKey(listing.SimpleAsset)
+}
+
+// Listings pairs each listing with the rules it matched, keeping the order of
+// the listings.
+func Listings(assetList []steam.SimpleAsset, matches []steam.RuleMatch) []Listing {
+	highlights := map[string][]string{}
+	for _, match := range matches {
+		key := steam.ListingKey(match.Asset)
+		highlights[key] = append(highlights[key], match.Rule)
+	}
+
+	listings := make([]Listing, 0, len(assetList))
+	for _, asset := range assetList {
+		listings = append(listings, Listing{
+			SimpleAsset: asset,
+			Highlights:  highlights[steam.ListingKey(asset)],
+			Fingerprint: asset.Float.Fingerprint(),
+		})
+	}
+	return listings
+}
+
+// Printer prints batches of listings in one format.
+type Printer struct {
+	w        io.Writer
+	format   string
+	template *template.Template
+	// wroteHeader is set once the CSV header is written, so repeated batches
+	// such as watch scans form one CSV file.
+	wroteHeader bool
+}
+
+// NewPrinter creates a printer for a format: json, table, csv, ndjson or
+// template=<text/template>.
+func NewPrinter(w io.Writer, format string) (*Printer, error) {
+	printer := &Printer{w: w, format: strings.ToLower(format)}
+
+	switch {
+	case strings.HasPrefix(format, templatePrefix):
+		tmpl, err := template.New("listing").Parse(strings.TrimPrefix(format, templatePrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid output template: %s", err)
+		}
+		printer.format = templatePrefix
+		printer.template = tmpl
+	case printer.format == "":
+		printer.format = JSON
+	case printer.format != JSON && printer.format != Table && printer.format != CSV && printer.format != NDJSON:
+		return nil, fmt.Errorf("unknown output format %q, expected json, table, csv, ndjson or template=<template>", format)
+	}
+
+	return printer, nil
+}
+
+// Print prints a batch of listings.
+func (printer *Printer) Print(listings []Listing) error {
+	switch printer.format {
+	case Table:
+		return printer.printTable(listings)
+	case CSV:
+		return printer.printCSV(listings)
+	case NDJSON:
+		return printer.printNDJSON(listings)
+	case templatePrefix:
+		return printer.printTemplate(listings)
+	}
+	return printer.printJSON(listings)
+}
+
+// printJSON prints the listings as an indented JSON array.
+func (printer *Printer) printJSON(listings []Listing) error {
+	listingJSON, err := json.MarshalIndent(listings, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to marshal listing JSON: %s", err)
+	}
+	_, err = fmt.Fprintf(printer.w, "%s\n", listingJSON)
+	return err
+}
+
+// printNDJSON prints each listing as JSON on its own line.
+func (printer *Printer) printNDJSON(listings []Listing) error {
+	encoder := json.NewEncoder(printer.w)
+	for _, listing := range listings {
+		err := encoder.Encode(listing)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printTable prints the listings in aligned columns, marking highlights.
+func (printer *Printer) printTable(listings []Listing) error {
+	tw := tabwriter.NewWriter(printer.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LISTING\tNAME\tSEED\tFLOAT\tPRICE\tTIER\tDEAL\tPROFIT\tHIGHLIGHTS")
+	for _, listing := range listings {
+		deal := "-"
+		if listing.Deal != nil {
+			deal = strconv.FormatFloat(listing.Deal.Score, 'f', 1, 64)
+		}
+		// Show the profit at the target price when one was given, as it was
+		// asked for, or else at the median.
+		profit := "-"
+		if listing.Profit != nil && listing.Profit.TargetPrice != 0 {
+			profit = strconv.FormatFloat(listing.Profit.TargetProfit, 'f', 2, 64)
+		} else if listing.Profit != nil && listing.Profit.MedianNet != 0 {
+			profit = strconv.FormatFloat(listing.Profit.MedianProfit, 'f', 2, 64)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.6f\t%s\t%s\t%s\t%s\t%s\n",
+			steam.ListingKey(listing.SimpleAsset),
+			listing.Name,
+			listing.Float.PaintSeed,
+			listing.Float.FloatValue,
+			listing.ListingTotalPrice,
+			formatTier(listing.RarityTier),
+			deal,
+			profit,
+			strings.Join(listing.Highlights, ", "))
+	}
+	return tw.Flush()
+}
+
+// printCSV prints the listings as CSV rows, writing the header before the
+// first batch.
+func (printer *Printer) printCSV(listings []Listing) error {
+	w := csv.NewWriter(printer.w)
+	if !printer.wroteHeader {
+		w.Write(csvHeader)
+		printer.wroteHeader = true
+	}
+	for _, listing := range listings {
+		dealScore, comparableDiscount := "", ""
+		if listing.Deal != nil {
+			dealScore = strconv.FormatFloat(listing.Deal.Score, 'f', -1, 64)
+			comparableDiscount = strconv.FormatFloat(listing.Deal.ComparableDiscount, 'f', -1, 64)
+		}
+		medianProfit, targetProfit := "", ""
+		if listing.Profit != nil && listing.Profit.MedianNet != 0 {
+			medianProfit = strconv.FormatFloat(listing.Profit.MedianProfit, 'f', 2, 64)
+		}
+		if listing.Profit != nil && listing.Profit.TargetPrice != 0 {
+			targetProfit = strconv.FormatFloat(listing.Profit.TargetProfit, 'f', 2, 64)
+		}
+		w.Write([]string{
+			listing.ListingID,
+			listing.ID,
+			listing.Name,
+			listing.Variant,
+			string(listing.Quality.Wear),
+			strconv.Itoa(listing.Float.PaintSeed),
+			strconv.FormatFloat(listing.Float.FloatValue, 'f', -1, 64),
+			listing.ListingPrice,
+			listing.ListingFee,
+			listing.ListingTotalPrice,
+			listing.ListingCurrency,
+			strconv.Itoa(listing.RarityTier),
+			dealScore,
+			comparableDiscount,
+			medianProfit,
+			targetProfit,
+			strings.Join(listing.Highlights, ";"),
+			listing.InspectURL,
+			listing.ScreenshotURL,
+			listing.Fingerprint,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// printTemplate executes the template for each listing, ending each with a
+// newline unless the template already does.
+func (printer *Printer) printTemplate(listings []Listing) error {
+	for _, listing := range listings {
+		text := strings.Builder{}
+		err := printer.template.Execute(&text, listing)
+		if err != nil {
+			return fmt.Errorf("failed to execute output template: %s", err)
+		}
+		if !strings.HasSuffix(text.String(), "\n") {
+			text.WriteString("\n")
+		}
+		_, err = io.WriteString(printer.w, text.String())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatTier shows a pattern tier, or "-" for ordinary patterns.
+func formatTier(tier int) string {
+	if tier == 0 {
+		return "-"
+	}
+	return strconv.Itoa(tier)
+}
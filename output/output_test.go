@@ -0,0 +1,149 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"eiffel65/float"
+	"eiffel65/steam"
+)
+
+var testAssets = []steam.SimpleAsset{
+	{
+		ID:                "11",
+		ListingID:         "101",
+		Name:              "AK-47 | Case Hardened (Field-Tested)",
+		ListingPrice:      "87.00",
+		ListingFee:        "13.05",
+		ListingTotalPrice: "100.05",
+		ListingCurrency:   "2",
+		RarityTier:        1,
+		Deal:              &steam.DealScore{Score: 82.5, ComparableDiscount: 31.25, Comparables: 3},
+		Profit:            &steam.ProfitEstimate{Cost: 100.05, MedianNet: 130.43, MedianProfit: 30.38},
+		Float:             float.AssetFloat{DefIndex: 7, PaintIndex: 44, PaintSeed: 661, PaintWear: 1046887630, FloatValue: 0.2113},
+	},
+	{
+		ID:                "12",
+		ListingID:         "102",
+		Name:              "AK-47 | Case Hardened (Field-Tested)",
+		ListingTotalPrice: "20.00",
+		Float:             float.AssetFloat{PaintSeed: 12, FloatValue: 0.3},
+	},
+}
+
+var testMatches = []steam.RuleMatch{
+	{Rule: "blue gems", Asset: testAssets[0]},
+	{Rule: "rare pattern", Asset: testAssets[0]},
+}
+
+func printListings(t *testing.T, format string) string {
+	t.Helper()
+	buffer := bytes.Buffer{}
+	printer, err := NewPrinter(&buffer, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = printer.Print(Listings(testAssets, testMatches))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return buffer.String()
+}
+
+func TestListings(t *testing.T) {
+	listings := Listings(testAssets, testMatches)
+	if len(listings) != 2 || !listings[0].Highlighted() || listings[1].Highlighted() {
+		t.Fatalf("got %+v", listings)
+	}
+	if strings.Join(listings[0].Highlights, ",") != "blue gems,rare pattern" {
+		t.Errorf("got highlights %v", listings[0].Highlights)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	listings := []Listing{}
+	err := json.Unmarshal([]byte(printListings(t, "")), &listings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listings[0].ListingID != "101" || listings[0].Highlights[0] != "blue gems" || listings[1].Highlights != nil {
+		t.Errorf("got %+v", listings)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(printListings(t, NDJSON)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want one per listing", len(lines))
+	}
+	listing := map[string]interface{}{}
+	err := json.Unmarshal([]byte(lines[0]), &listing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listing["listing_id"] != "101" || listing["highlights"].([]interface{})[1] != "rare pattern" {
+		t.Errorf("got %v", listing)
+	}
+}
+
+func TestTable(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(printListings(t, Table)), "\n")
+	want := []string{
+		"LISTING  NAME                                  SEED  FLOAT     PRICE   TIER  DEAL  PROFIT  HIGHLIGHTS",
+		"101      AK-47 | Case Hardened (Field-Tested)  661   0.211300  100.05  1     82.5  30.38   blue gems, rare pattern",
+		"102      AK-47 | Case Hardened (Field-Tested)  12    0.300000  20.00   -     -     -",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got:\n%s", strings.Join(lines, "\n"))
+	}
+	for i := range want {
+		if strings.TrimRight(lines[i], " ") != want[i] {
+			t.Errorf("got %q, want %q", lines[i], want[i])
+		}
+	}
+}
+
+func TestCSV(t *testing.T) {
+	buffer := bytes.Buffer{}
+	printer, err := NewPrinter(&buffer, CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		err = printer.Print(Listings(testAssets, testMatches))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("got %d records, want a single header and four rows", len(records))
+	}
+	if records[0][0] != "listing_id" || records[1][5] != "661" || records[1][6] != "0.2113" || records[1][12] != "82.5" || records[1][13] != "31.25" ||
+		records[1][14] != "30.38" || records[1][15] != "" || records[1][16] != "blue gems;rare pattern" || records[2][12] != "" ||
+		records[1][19] != "7-44-661-1046887630" || records[2][19] != "" {
+		t.Errorf("got %v", records[:2])
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	got := printListings(t, "template={{.ListingID}} {{.Float.PaintSeed}}{{if .Highlighted}} *{{end}}")
+	if got != "101 661 *\n102 12\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestNewPrinterInvalid(t *testing.T) {
+	for _, format := range []string{"xml", "template={{.Name"} {
+		if _, err := NewPrinter(&bytes.Buffer{}, format); err == nil {
+			t.Errorf("%q: expected an error", format)
+		}
+	}
+}
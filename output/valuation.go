@@ -0,0 +1,154 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"eiffel65/steam"
+)
+
+// valuationCSVHeader names the CSV columns of a valuation. The row column
+// tells the items from the category and grand totals after them.
+var valuationCSVHeader = []string{
+	"row", "category", "id", "name", "rarity_tier", "items", "priced",
+	"unpriced", "market_price", "sticker_premium", "rarity_premium", "value", "currency",
+}
+
+// CheckValuationFormat reports whether valuations can be printed in the
+// format, so a bad one fails before the inventory is priced.
+func CheckValuationFormat(format string) error {
+	switch strings.ToLower(format) {
+	case JSON, Table, CSV, "":
+		return nil
+	}
+	return fmt.Errorf("unknown valuation format %q, expected json, table or csv", format)
+}
+
+// PrintValuation prints what an inventory is worth as JSON, a table or CSV.
+func PrintValuation(w io.Writer, format string, valuation steam.Valuation) error {
+	err := CheckValuationFormat(format)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(format) {
+	case Table:
+		return printValuationTable(w, valuation)
+	case CSV:
+		return printValuationCSV(w, valuation)
+	}
+
+	valuationJSON, err := json.MarshalIndent(valuation, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to marshal valuation JSON: %s", err)
+	}
+	_, err = fmt.Fprintf(w, "%s\n", valuationJSON)
+	return err
+}
+
+// printValuationTable prints the items, then the categories and total, in
+// aligned columns.
+func printValuationTable(w io.Writer, valuation steam.Valuation) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ITEM\tNAME\tCATEGORY\tTIER\tPRICE\tSTICKERS\tRARITY\tVALUE (%s)\n", valuation.Currency)
+	for _, item := range valuation.Items {
+		price := "-"
+		if item.Priced {
+			price = formatMoney(item.MarketPrice)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			item.ID,
+			item.Name,
+			item.Category,
+			formatTier(item.RarityTier),
+			price,
+			formatMoney(item.StickerPremium),
+			formatMoney(item.RarityPremium),
+			formatMoney(item.Value))
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "CATEGORY\tITEMS\tPRICED\tUNPRICED\tPRICE\tSTICKERS\tRARITY\tVALUE (%s)\n", valuation.Currency)
+	for _, category := range append(valuation.Categories, valuation.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			category.Category,
+			category.Items,
+			category.Priced,
+			category.Unpriced,
+			formatMoney(category.MarketPrice),
+			formatMoney(category.StickerPremium),
+			formatMoney(category.RarityPremium),
+			formatMoney(category.Value))
+	}
+	return tw.Flush()
+}
+
+// printValuationCSV prints a row for each item, then for each category and
+// the total, for bookkeeping.
+func printValuationCSV(w io.Writer, valuation steam.Valuation) error {
+	cw := csv.NewWriter(w)
+	cw.Write(valuationCSVHeader)
+	for _, item := range valuation.Items {
+		priced, unpriced := "0", "0"
+		if item.Priced {
+			priced = "1"
+		} else if item.Marketable {
+			unpriced = "1"
+		}
+		cw.Write([]string{
+			"item",
+			item.Category,
+			item.ID,
+			item.Name,
+			strconv.Itoa(item.RarityTier),
+			"1",
+			priced,
+			unpriced,
+			formatMoney(item.MarketPrice),
+			formatMoney(item.StickerPremium),
+			formatMoney(item.RarityPremium),
+			formatMoney(item.Value),
+			valuation.Currency,
+		})
+	}
+	for _, category := range valuation.Categories {
+		cw.Write(categoryCSVRow("category", category, valuation.Currency))
+	}
+	cw.Write(categoryCSVRow("total", valuation.Total, valuation.Currency))
+	cw.Flush()
+	return cw.Error()
+}
+
+// categoryCSVRow is the CSV row of the totals of a category.
+func categoryCSVRow(row string, category steam.CategoryValue, currency string) []string {
+	return []string{
+		row,
+		category.Category,
+		"",
+		"",
+		"",
+		strconv.Itoa(category.Items),
+		strconv.Itoa(category.Priced),
+		strconv.Itoa(category.Unpriced),
+		formatMoney(category.MarketPrice),
+		formatMoney(category.StickerPremium),
+		formatMoney(category.RarityPremium),
+		formatMoney(category.Value),
+		currency,
+	}
+}
+
+// formatMoney shows an amount to the cent.
+func formatMoney(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
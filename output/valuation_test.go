@@ -0,0 +1,94 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"eiffel65/steam"
+)
+
+var testValuation = steam.ValueAssets([]steam.SimpleAsset{
+	{ID: "1", Name: "AK-47 | Case Hardened (Field-Tested)", Type: "weapon", Marketable: true, RarityTier: 1, StickerValue: 20, MarketValue: steam.AssetValue{MedianPrice: "£100.00"}},
+	{ID: "2", Name: "Revolution Case", Type: "case"},
+	{ID: "3", Name: "AWP | Asiimov (Field-Tested)", Type: "weapon", Marketable: true},
+}, steam.Premiums{Sticker: 0.1, Rarity: 0.5}, "GBP")
+
+func TestValuationTable(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := PrintValuation(&buffer, Table, testValuation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	want := []string{
+		"ITEM  NAME                                  CATEGORY  TIER  PRICE   STICKERS  RARITY  VALUE (GBP)",
+		"1     AK-47 | Case Hardened (Field-Tested)  weapon    1     100.00  2.00      50.00   152.00",
+		"2     Revolution Case                       case      -     -       0.00      0.00    0.00",
+		"3     AWP | Asiimov (Field-Tested)          weapon    -     -       0.00      0.00    0.00",
+		"",
+		"CATEGORY  ITEMS  PRICED  UNPRICED  PRICE   STICKERS  RARITY  VALUE (GBP)",
+		"weapon    2      1       1         100.00  2.00      50.00   152.00",
+		"case      1      0       0         0.00    0.00      0.00    0.00",
+		"total     3      1       1         100.00  2.00      50.00   152.00",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got:\n%s", strings.Join(lines, "\n"))
+	}
+	for i := range want {
+		if strings.TrimRight(lines[i], " ") != want[i] {
+			t.Errorf("got %q, want %q", lines[i], want[i])
+		}
+	}
+}
+
+func TestValuationCSV(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := PrintValuation(&buffer, CSV, testValuation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		valuationCSVHeader,
+		{"item", "weapon", "1", "AK-47 | Case Hardened (Field-Tested)", "1", "1", "1", "0", "100.00", "2.00", "50.00", "152.00", "GBP"},
+		{"item", "case", "2", "Revolution Case", "0", "1", "0", "0", "0.00", "0.00", "0.00", "0.00", "GBP"},
+		{"item", "weapon", "3", "AWP | Asiimov (Field-Tested)", "0", "1", "0", "1", "0.00", "0.00", "0.00", "0.00", "GBP"},
+		{"category", "weapon", "", "", "", "2", "1", "1", "100.00", "2.00", "50.00", "152.00", "GBP"},
+		{"category", "case", "", "", "", "1", "0", "0", "0.00", "0.00", "0.00", "0.00", "GBP"},
+		{"total", "total", "", "", "", "3", "1", "1", "100.00", "2.00", "50.00", "152.00", "GBP"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("got\n%v\nwant\n%v", records, want)
+	}
+}
+
+func TestValuationJSON(t *testing.T) {
+	buffer := bytes.Buffer{}
+	err := PrintValuation(&buffer, JSON, testValuation)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valuation := steam.Valuation{}
+	err = json.Unmarshal(buffer.Bytes(), &valuation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valuation.Total.Value != 152 || valuation.Total.Unpriced != 1 || len(valuation.Items) != 3 || valuation.Currency != "GBP" {
+		t.Errorf("got %+v", valuation)
+	}
+
+	err = PrintValuation(&buffer, NDJSON, testValuation)
+	if err == nil {
+		t.Error("expected an error for a format valuations are not printed in")
+	}
+}
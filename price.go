@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// runPrice looks up the price overview of an item by its market hash name.
+func runPrice(flags *flag.FlagSet, args []string) error {
+	clientFlags := clientOptions{}
+	clientFlags.register(flags)
+	flags.Parse(args)
+
+	marketHashName := strings.Join(flags.Args(), " ")
+	if marketHashName == "" {
+		return fmt.Errorf("please specify a market hash name, e.g. \"AK-47 | Redline (Field-Tested)\"")
+	}
+
+	settings, err := clientFlags.load()
+	if err != nil {
+		return err
+	}
+
+	steamClient, err := clientFlags.newClient(settings, false)
+	if err != nil {
+		return err
+	}
+
+	marketValue, err := steamClient.GetPriceOverview(marketHashName)
+	if err != nil {
+		return fmt.Errorf("failed to get price overview: %s", err)
+	}
+
+	return printJSON(marketValue)
+}
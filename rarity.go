@@ -0,0 +1,65 @@
+package main
+
+import (
+	"eiffel65/schema"
+	"eiffel65/steam"
+	"flag"
+	"fmt"
+)
+
+// runRarity runs the rarity subcommands, of which there is only check.
+func runRarity(flags *flag.FlagSet, args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		flags.Usage()
+		return fmt.Errorf("unknown rarity command, expected check")
+	}
+	return runRarityCheck(flags, args[1:])
+}
+
+// runRarityCheck prints the pattern tier of an item, either inspected from
+// its link or given by its DefIndex and paint seed.
+func runRarityCheck(flags *flag.FlagSet, args []string) error {
+	clientFlags := clientOptions{}
+	clientFlags.register(flags)
+	defIndex := flags.Int("def", 0, "the DefIndex of the weapon, instead of an inspect link")
+	paintSeed := flags.Int("seed", -1, "the paint seed of the pattern, instead of an inspect link")
+	flags.Parse(args)
+
+	name := ""
+	switch {
+	case flags.NArg() == 1:
+		settings, err := clientFlags.load()
+		if err != nil {
+			return err
+		}
+
+		steamClient, err := clientFlags.newClient(settings, false)
+		if err != nil {
+			return err
+		}
+
+		asset, err := steamClient.Inspect(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("failed to inspect item: %s", err)
+		}
+		name, *defIndex, *paintSeed = asset.Name, asset.Float.DefIndex, asset.Float.PaintSeed
+	case flags.NArg() == 0 && *defIndex != 0 && *paintSeed >= 0:
+		itemSchema, err := schema.Default()
+		if err != nil {
+			return err
+		}
+		if weapon, ok := itemSchema.Weapon(*defIndex); ok {
+			name = weapon.Name
+		}
+	default:
+		return fmt.Errorf("please specify an inspect link, or -def and -seed")
+	}
+
+	tier := steam.PatternTier(*defIndex, *paintSeed)
+	if tier == 0 {
+		fmt.Printf("NAME: %s DEFINDEX: %d SEED: %d TIER: none, not a rare pattern\n", name, *defIndex, *paintSeed)
+		return nil
+	}
+	fmt.Printf("NAME: %s DEFINDEX: %d SEED: %d TIER: %d\n", name, *defIndex, *paintSeed, tier)
+	return nil
+}
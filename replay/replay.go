@@ -0,0 +1,183 @@
+// Package replay records the responses of the Steam market, price overview
+// and float APIs to fixture files, and serves them back later, so scans can
+// be run offline and in tests with real-shaped data.
+package replay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"eiffel65/metrics"
+)
+
+// secretParams are left out of fixtures so they can be checked in.
+var secretParams = []string{"key"}
+
+// Fixture is a response saved to a file.
+type Fixture struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	// JSON holds the body when it is JSON, so the fixture stays readable and
+	// easy to edit, and Body holds it otherwise.
+	JSON json.RawMessage `json:"json,omitempty"`
+	Body string          `json:"body,omitempty"`
+}
+
+// recorder saves every response of the round tripper it wraps.
+type recorder struct {
+	dir  string
+	base http.RoundTripper
+}
+
+// NewRecorder wraps a round tripper so every response it gets is saved as a
+// fixture in the directory. A nil round tripper wraps http.DefaultTransport.
+func NewRecorder(dir string, base http.RoundTripper) (http.RoundTripper, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %s", err)
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return recorder{dir: dir, base: base}, nil
+}
+
+// RoundTrip makes the request and saves its response.
+func (r recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := r.base.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	fixture := Fixture{
+		Method:      request.Method,
+		URL:         redact(request.URL).String(),
+		Status:      response.StatusCode,
+		ContentType: response.Header.Get("Content-Type"),
+	}
+	if json.Valid(body) {
+		fixture.JSON = body
+	} else {
+		fixture.Body = string(body)
+	}
+
+	fixtureJSON := bytes.Buffer{}
+	encoder := json.NewEncoder(&fixtureJSON)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(fixture)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fixture: %s", err)
+	}
+	err = os.WriteFile(filepath.Join(r.dir, FileName(request)), fixtureJSON.Bytes(), 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save fixture: %s", err)
+	}
+	return response, nil
+}
+
+// replayer answers requests from fixtures.
+type replayer struct {
+	dir string
+}
+
+// NewReplayer answers every request from the fixtures in the directory,
+// failing those without one rather than going to the network.
+func NewReplayer(dir string) (http.RoundTripper, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fixture directory: %s", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return replayer{dir: dir}, nil
+}
+
+// RoundTrip answers the request from its fixture.
+func (r replayer) RoundTrip(request *http.Request) (*http.Response, error) {
+	fileName := FileName(request)
+	fixtureJSON, err := os.ReadFile(filepath.Join(r.dir, fileName))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no fixture %s, record it with -record", fileName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := Fixture{}
+	err = json.Unmarshal(fixtureJSON, &fixture)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture %s: %s", fileName, err)
+	}
+
+	body := []byte(fixture.Body)
+	if fixture.JSON != nil {
+		// Undo the indenting the fixture was saved with.
+		compacted := bytes.Buffer{}
+		err = json.Compact(&compacted, fixture.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %s", fileName, err)
+		}
+		body = compacted.Bytes()
+	}
+	header := http.Header{}
+	if fixture.ContentType != "" {
+		header.Set("Content-Type", fixture.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Status, http.StatusText(fixture.Status)),
+		StatusCode:    fixture.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}, nil
+}
+
+// FileName is the fixture file of a request, named by its upstream and a
+// hash of its method, path and query. The host is left out so fixtures
+// recorded against one provider replay against another, and secrets are
+// left out so recording with a key and replaying without one match.
+func FileName(request *http.Request) string {
+	redacted := redact(request.URL)
+	hash := sha256.Sum256([]byte(request.Method + " " + redacted.Path + "?" + redacted.RawQuery))
+	upstream := metrics.Upstream(request.URL)
+	upstream = strings.NewReplacer(":", "_", "/", "_").Replace(upstream)
+	return upstream + "-" + hex.EncodeToString(hash[:8]) + ".json"
+}
+
+// redact copies the URL without its secrets, leaving the query as it was
+// if it has none.
+func redact(requestURL *url.URL) *url.URL {
+	redacted := *requestURL
+	query := redacted.Query()
+	for _, param := range secretParams {
+		if query.Has(param) {
+			query.Del(param)
+			redacted.RawQuery = query.Encode()
+		}
+	}
+	return &redacted
+}
@@ -0,0 +1,93 @@
+package replay
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/market/priceoverview":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"success":true,"lowest_price":"£10.00"}`)
+		case "/market/listings/730/AK-47/render":
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, "slow down")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recording := &http.Client{Transport: recorder}
+	paths := []string{"/market/priceoverview?market_hash_name=AK-47&key=secret", "/market/listings/730/AK-47/render"}
+	want := []string{}
+	for _, path := range paths {
+		response, err := recording.Get(upstream.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		want = append(want, response.Status+" "+string(body))
+	}
+
+	fixtures, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(fixtures) != 2 {
+		t.Fatalf("got fixtures %q, want 2", fixtures)
+	}
+	for _, fixture := range fixtures {
+		fixtureJSON, _ := os.ReadFile(fixture)
+		if strings.Contains(string(fixtureJSON), "secret") {
+			t.Errorf("%s keeps the API key:\n%s", fixture, fixtureJSON)
+		}
+	}
+
+	// Replayed against another host and without the key, once the upstream
+	// is gone.
+	upstream.Close()
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaying := &http.Client{Transport: replayer}
+	for i, path := range []string{"/market/priceoverview?market_hash_name=AK-47", "/market/listings/730/AK-47/render"} {
+		response, err := replaying.Get("https://steamcommunity.com" + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		if got := response.Status + " " + string(body); got != want[i] {
+			t.Errorf("%s: got %q, want %q", path, got, want[i])
+		}
+	}
+
+	_, err = replaying.Get("https://steamcommunity.com/market/priceoverview?market_hash_name=M4A4")
+	if err == nil || !strings.Contains(err.Error(), "no fixture price_overview-") {
+		t.Errorf("expected a missing fixture error, got %v", err)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "https://api.csgofloat.com/?url=steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20M%listingid%A1D2", nil)
+	if got := FileName(request); !strings.HasPrefix(got, "csgofloat-") || !strings.HasSuffix(got, ".json") {
+		t.Errorf("got %q", got)
+	}
+
+	other := httptest.NewRequest(http.MethodGet, "https://api.csgofloat.com/?url=steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20M%listingid%A1D3", nil)
+	if FileName(request) == FileName(other) {
+		t.Errorf("expected different inspect links to have different fixtures")
+	}
+}
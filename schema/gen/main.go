@@ -0,0 +1,54 @@
+// Command gen builds the bundled schema.json from the game's items_game.txt
+// and language file.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"eiffel65/schema"
+)
+
+func main() {
+	itemsPath := flag.String("items", "items_game.txt", "path to the game's items_game.txt")
+	languagePath := flag.String("lang", "csgo_english.txt", "path to the game's language file")
+	outPath := flag.String("out", "schema.json", "where to write the schema")
+	minPaintKits := flag.Int("min-paint-kits", 500, "refuse to write a schema with fewer paint kits, as it was built from an excerpt rather than the full file")
+	flag.Parse()
+
+	itemsGame, err := os.Open(*itemsPath)
+	if err != nil {
+		log.Fatalf("failed to open items_game: %s", err)
+	}
+	defer itemsGame.Close()
+
+	language, err := os.Open(*languagePath)
+	if err != nil {
+		log.Fatalf("failed to open language file: %s", err)
+	}
+	defer language.Close()
+
+	generated, err := schema.Generate(itemsGame, language)
+	if err != nil {
+		log.Fatalf("failed to generate schema: %s", err)
+	}
+
+	if len(generated.PaintKits) < *minPaintKits {
+		log.Fatalf("only found %d paint kits in %s, expected at least %d from the full file", len(generated.PaintKits), *itemsPath, *minPaintKits)
+	}
+
+	schemaJSON, err := json.MarshalIndent(generated, "", "\t")
+	if err != nil {
+		log.Fatalf("failed to marshal schema JSON: %s", err)
+	}
+
+	err = os.WriteFile(*outPath, append(schemaJSON, '\n'), 0644)
+	if err != nil {
+		log.Fatalf("failed to write schema: %s", err)
+	}
+
+	log.Printf("wrote %d weapons, %d paint kits and %d skins to %s",
+		len(generated.Weapons), len(generated.PaintKits), len(generated.Skins), *outPath)
+}
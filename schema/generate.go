@@ -0,0 +1,348 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	iconPathPrefix     string  = "econ/default_generated/"
+	knifePrefab        string  = "melee_unusual"
+	glovesPrefab       string  = "hands_paintable"
+	defaultWearMin     float64 = 0.06
+	defaultWearMax     float64 = 0.8
+	defaultPaintKitKey string  = "0"
+)
+
+// rarityCodes are the items_game names of each rarity.
+var rarityCodes = map[string]int{
+	"default":   RarityDefault,
+	"common":    RarityConsumer,
+	"uncommon":  RarityIndustrial,
+	"rare":      RarityMilSpec,
+	"mythical":  RarityRestricted,
+	"legendary": RarityClassified,
+	"ancient":   RarityCovert,
+	"immortal":  RarityContraband,
+}
+
+// Generate builds a schema from the game's items_game.txt and a language file
+// such as csgo_english.txt, which holds the display names.
+func Generate(itemsGame, language io.Reader) (*Schema, error) {
+	itemsRoot, err := parseKeyValues(itemsGame)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse items_game: %s", err)
+	}
+	languageRoot, err := parseKeyValues(language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse language file: %s", err)
+	}
+
+	items := itemsRoot.children("items_game")
+	if len(items) == 0 {
+		return nil, errors.New("no items_game block found")
+	}
+	generator := generator{
+		items:   items[0],
+		tokens:  map[string]string{},
+		prefabs: map[string]*kvNode{},
+	}
+
+	for _, lang := range languageRoot.children("lang") {
+		for _, token := range lang.merged("Tokens") {
+			generator.tokens[strings.ToLower(token.Key)] = token.Value
+		}
+	}
+	for _, prefab := range generator.items.merged("prefabs") {
+		generator.prefabs[prefab.Key] = prefab
+	}
+
+	return generator.build(), nil
+}
+
+// generator holds the parsed files while a schema is built.
+type generator struct {
+	items   *kvNode
+	tokens  map[string]string
+	prefabs map[string]*kvNode
+}
+
+// build assembles the weapons, paint kits and skins.
+func (generator *generator) build() *Schema {
+	weapons := generator.weapons()
+	paintKits := generator.paintKits()
+
+	weaponsByClass := map[string]Weapon{}
+	for _, weapon := range weapons {
+		weaponsByClass[weapon.ClassName] = weapon
+	}
+	paintKitsByCode := map[string]PaintKit{}
+	for _, paintKit := range paintKits {
+		paintKitsByCode[paintKit.CodeName] = paintKit
+	}
+
+	skins := map[[2]int]*Skin{}
+	addSkin := func(weaponClass, paintCode string) *Skin {
+		weapon, ok := weaponsByClass[weaponClass]
+		if !ok {
+			return nil
+		}
+		paintKit, ok := paintKitsByCode[paintCode]
+		if !ok {
+			return nil
+		}
+
+		key := [2]int{weapon.DefIndex, paintKit.PaintIndex}
+		if skins[key] == nil {
+			rarity := paintKit.Rarity
+			if weapon.Kind != KindWeapon {
+				rarity = RarityCovert
+			}
+			skins[key] = &Skin{DefIndex: weapon.DefIndex, PaintIndex: paintKit.PaintIndex, Rarity: rarity}
+		}
+		return skins[key]
+	}
+
+	// Collections, keyed "[paint]weapon".
+	for _, set := range generator.items.merged("item_sets") {
+		collection := generator.localise(set.value("name"))
+		for _, item := range set.merged("items") {
+			weaponClass, paintCode, ok := splitLootName(item.Key)
+			if !ok {
+				continue
+			}
+			if skin := addSkin(weaponClass, paintCode); skin != nil {
+				skin.Collection = collection
+			}
+		}
+	}
+
+	// Loot lists name the rarity of each skin in their suffix, e.g.
+	// "set_weapons_i_legendary".
+	for _, lootList := range generator.items.merged("client_loot_lists") {
+		rarity, ok := lootListRarity(lootList.Key)
+		if !ok {
+			continue
+		}
+		for _, item := range lootList.Children {
+			weaponClass, paintCode, ok := splitLootName(item.Key)
+			if !ok {
+				continue
+			}
+			if skin := addSkin(weaponClass, paintCode); skin != nil && weaponsByClass[weaponClass].Kind == KindWeapon {
+				skin.Rarity = rarity
+			}
+		}
+	}
+
+	// Knives and gloves are not in collections, so find their skins from the
+	// generated icons, e.g. "econ/default_generated/weapon_knife_falchion_aq_oiled_light".
+	for _, icons := range generator.items.children("alternate_icons2") {
+		for _, icon := range icons.merged("weapon_icons") {
+			weaponClass, paintCode, ok := splitIconPath(icon.value("icon_path"), weaponsByClass)
+			if ok {
+				addSkin(weaponClass, paintCode)
+			}
+		}
+	}
+
+	schema := Schema{Weapons: weapons, PaintKits: paintKits, Skins: []Skin{}}
+	for _, skin := range skins {
+		schema.Skins = append(schema.Skins, *skin)
+	}
+	sort.Slice(schema.Skins, func(i, j int) bool {
+		if schema.Skins[i].DefIndex != schema.Skins[j].DefIndex {
+			return schema.Skins[i].DefIndex < schema.Skins[j].DefIndex
+		}
+		return schema.Skins[i].PaintIndex < schema.Skins[j].PaintIndex
+	})
+
+	schema.index()
+	return &schema
+}
+
+// weapons reads the guns, knives and gloves from the items block.
+func (generator *generator) weapons() []Weapon {
+	weapons := []Weapon{}
+	for _, item := range generator.items.merged("items") {
+		defIndex, err := strconv.Atoi(item.Key)
+		if err != nil {
+			continue
+		}
+
+		className := item.value("name")
+		kind := ""
+		switch {
+		case generator.hasPrefab(item, knifePrefab):
+			kind = KindKnife
+		case generator.hasPrefab(item, glovesPrefab):
+			kind = KindGloves
+		case strings.HasPrefix(className, "weapon_") && !generator.hasPrefab(item, "melee"):
+			kind = KindWeapon
+		default:
+			continue
+		}
+
+		name := generator.localise(generator.resolve(item, "item_name"))
+		if name == "" {
+			continue
+		}
+
+		weapons = append(weapons, Weapon{DefIndex: defIndex, ClassName: className, Name: name, Kind: kind})
+	}
+
+	sort.Slice(weapons, func(i, j int) bool { return weapons[i].DefIndex < weapons[j].DefIndex })
+	return weapons
+}
+
+// paintKits reads the paint kits with their rarity and wear caps.
+func (generator *generator) paintKits() []PaintKit {
+	rarities := map[string]int{}
+	for _, rarity := range generator.items.merged("paint_kits_rarity") {
+		rarities[rarity.Key] = rarityCodes[rarity.Value]
+	}
+
+	wearMin, wearMax := defaultWearMin, defaultWearMax
+	for _, paintKit := range generator.items.merged("paint_kits") {
+		if paintKit.Key == defaultPaintKitKey {
+			wearMin = parseFloat(paintKit.value("wear_remap_min"), wearMin)
+			wearMax = parseFloat(paintKit.value("wear_remap_max"), wearMax)
+		}
+	}
+
+	paintKits := []PaintKit{}
+	for _, paintKit := range generator.items.merged("paint_kits") {
+		paintIndex, err := strconv.Atoi(paintKit.Key)
+		if err != nil || paintKit.Key == defaultPaintKitKey {
+			continue
+		}
+
+		name := generator.localise(paintKit.value("description_tag"))
+		if name == "" {
+			continue
+		}
+
+		codeName := paintKit.value("name")
+		paintKits = append(paintKits, PaintKit{
+			PaintIndex: paintIndex,
+			CodeName:   codeName,
+			Name:       name,
+			Rarity:     rarities[codeName],
+			WearMin:    parseFloat(paintKit.value("wear_remap_min"), wearMin),
+			WearMax:    parseFloat(paintKit.value("wear_remap_max"), wearMax),
+		})
+	}
+
+	sort.Slice(paintKits, func(i, j int) bool { return paintKits[i].PaintIndex < paintKits[j].PaintIndex })
+	return paintKits
+}
+
+// resolve finds a value on an item or, failing that, on its prefabs.
+func (generator *generator) resolve(node *kvNode, key string) string {
+	return generator.resolveDepth(node, key, 0)
+}
+
+// resolveDepth is resolve with a guard against prefab cycles.
+func (generator *generator) resolveDepth(node *kvNode, key string, depth int) string {
+	if value := node.value(key); value != "" || depth > 16 {
+		return value
+	}
+	for _, prefabName := range strings.Fields(node.value("prefab")) {
+		if prefab, ok := generator.prefabs[prefabName]; ok {
+			if value := generator.resolveDepth(prefab, key, depth+1); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// hasPrefab reports whether an item inherits from a prefab, directly or
+// through other prefabs.
+func (generator *generator) hasPrefab(node *kvNode, prefabName string) bool {
+	return generator.hasPrefabDepth(node, prefabName, 0)
+}
+
+// hasPrefabDepth is hasPrefab with a guard against prefab cycles.
+func (generator *generator) hasPrefabDepth(node *kvNode, prefabName string, depth int) bool {
+	if depth > 16 {
+		return false
+	}
+	for _, name := range strings.Fields(node.value("prefab")) {
+		if name == prefabName {
+			return true
+		}
+		if prefab, ok := generator.prefabs[name]; ok && generator.hasPrefabDepth(prefab, prefabName, depth+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// localise looks up a "#Token" in the language file.
+func (generator *generator) localise(token string) string {
+	if !strings.HasPrefix(token, "#") {
+		return token
+	}
+	return generator.tokens[strings.ToLower(strings.TrimPrefix(token, "#"))]
+}
+
+// splitLootName splits a loot list entry such as "[aq_oiled]weapon_ak47" into
+// its weapon class and paint code names.
+func splitLootName(name string) (string, string, bool) {
+	if !strings.HasPrefix(name, "[") {
+		return "", "", false
+	}
+	end := strings.Index(name, "]")
+	if end < 0 {
+		return "", "", false
+	}
+	return name[end+1:], name[1:end], true
+}
+
+// lootListRarity reads the rarity from the suffix of a loot list name.
+func lootListRarity(name string) (int, bool) {
+	i := strings.LastIndex(name, "_")
+	if i < 0 {
+		return 0, false
+	}
+	rarity, ok := rarityCodes[name[i+1:]]
+	return rarity, ok && rarity != RarityDefault
+}
+
+// splitIconPath splits a generated icon path into its weapon class and paint
+// code names, matching the longest weapon class so that weapon_knife_m9_bayonet
+// is not mistaken for weapon_knife.
+func splitIconPath(iconPath string, weaponsByClass map[string]Weapon) (string, string, bool) {
+	if !strings.HasPrefix(iconPath, iconPathPrefix) {
+		return "", "", false
+	}
+	name := strings.TrimPrefix(iconPath, iconPathPrefix)
+	for _, suffix := range []string{"_light", "_medium", "_heavy"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+
+	weaponClass := ""
+	for className := range weaponsByClass {
+		if strings.HasPrefix(name, className+"_") && len(className) > len(weaponClass) {
+			weaponClass = className
+		}
+	}
+	if weaponClass == "" {
+		return "", "", false
+	}
+	return weaponClass, strings.TrimPrefix(name, weaponClass+"_"), true
+}
+
+// parseFloat parses a wear value, falling back to a default.
+func parseFloat(value string, fallback float64) float64 {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
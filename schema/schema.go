@@ -0,0 +1,209 @@
+// Package schema resolves the numeric indexes used by the game, such as the
+// DefIndex and PaintIndex of an inspected item, into weapon names, paint
+// names, rarities, wear caps and collections.
+//
+// The bundled schema.json is generated by go generate from copies of the
+// game's full items_game.txt and csgo_english.txt placed in this directory.
+// The files in testdata are trimmed excerpts for the tests, which the
+// generator refuses to build the bundled schema from.
+package schema
+
+import (
+	_ "embed" // Embeds the bundled schema.
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:generate go run ./gen -items items_game.txt -lang csgo_english.txt -out schema.json
+
+//go:embed schema.json
+var bundledSchema []byte
+
+// Kinds of weapon.
+const (
+	KindWeapon = "weapon"
+	KindKnife  = "knife"
+	KindGloves = "gloves"
+)
+
+// Rarities, numbered as the game and the float API number them.
+const (
+	RarityDefault    = 0
+	RarityConsumer   = 1
+	RarityIndustrial = 2
+	RarityMilSpec    = 3
+	RarityRestricted = 4
+	RarityClassified = 5
+	RarityCovert     = 6
+	RarityContraband = 7
+)
+
+// rarityNames are the display names of each rarity for weapons.
+var rarityNames = map[int]string{
+	RarityDefault:    "Stock",
+	RarityConsumer:   "Consumer Grade",
+	RarityIndustrial: "Industrial Grade",
+	RarityMilSpec:    "Mil-Spec Grade",
+	RarityRestricted: "Restricted",
+	RarityClassified: "Classified",
+	RarityCovert:     "Covert",
+	RarityContraband: "Contraband",
+}
+
+// Schema is the item data for weapons, paint kits, the skins combining them
+// and the collections they belong to.
+type Schema struct {
+	Weapons   []Weapon   `json:"weapons"`
+	PaintKits []PaintKit `json:"paint_kits"`
+	Skins     []Skin     `json:"skins"`
+
+	weapons   map[int]Weapon
+	paintKits map[int]PaintKit
+	skins     map[[2]int]Skin
+}
+
+// Weapon is an item that can carry a paint, including knives and gloves.
+type Weapon struct {
+	DefIndex  int    `json:"defindex"`
+	ClassName string `json:"class_name"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+}
+
+// PaintKit is a finish that can be applied to a weapon.
+type PaintKit struct {
+	PaintIndex int     `json:"paintindex"`
+	CodeName   string  `json:"code_name"`
+	Name       string  `json:"name"`
+	Rarity     int     `json:"rarity"`
+	WearMin    float64 `json:"wear_min"`
+	WearMax    float64 `json:"wear_max"`
+}
+
+// Skin is a paint kit as it appears on a particular weapon.
+type Skin struct {
+	DefIndex   int    `json:"defindex"`
+	PaintIndex int    `json:"paintindex"`
+	Rarity     int    `json:"rarity"`
+	Collection string `json:"collection,omitempty"`
+}
+
+var (
+	defaultSchema     *Schema
+	defaultSchemaErr  error
+	defaultSchemaOnce sync.Once
+)
+
+// Default returns the bundled schema.
+func Default() (*Schema, error) {
+	defaultSchemaOnce.Do(func() {
+		defaultSchema, defaultSchemaErr = Load(bundledSchema)
+	})
+	return defaultSchema, defaultSchemaErr
+}
+
+// Load reads a schema from its JSON form.
+func Load(data []byte) (*Schema, error) {
+	schema := Schema{}
+	err := json.Unmarshal(data, &schema)
+	if err != nil {
+		return nil, err
+	}
+	schema.index()
+	return &schema, nil
+}
+
+// index builds the lookup tables for the schema.
+func (schema *Schema) index() {
+	schema.weapons = map[int]Weapon{}
+	for _, weapon := range schema.Weapons {
+		schema.weapons[weapon.DefIndex] = weapon
+	}
+
+	schema.paintKits = map[int]PaintKit{}
+	for _, paintKit := range schema.PaintKits {
+		schema.paintKits[paintKit.PaintIndex] = paintKit
+	}
+
+	schema.skins = map[[2]int]Skin{}
+	for _, skin := range schema.Skins {
+		schema.skins[[2]int{skin.DefIndex, skin.PaintIndex}] = skin
+	}
+}
+
+// Weapon looks up a weapon by its DefIndex.
+func (schema *Schema) Weapon(defIndex int) (Weapon, bool) {
+	weapon, ok := schema.weapons[defIndex]
+	return weapon, ok
+}
+
+// PaintKit looks up a paint kit by its PaintIndex.
+func (schema *Schema) PaintKit(paintIndex int) (PaintKit, bool) {
+	paintKit, ok := schema.paintKits[paintIndex]
+	return paintKit, ok
+}
+
+// PaintKitsNamed looks up every paint kit with a display name, ignoring case.
+// Several paint kits can share a name, such as the phases of Doppler.
+func (schema *Schema) PaintKitsNamed(name string) []PaintKit {
+	paintKits := []PaintKit{}
+	for _, paintKit := range schema.PaintKits {
+		if strings.EqualFold(paintKit.Name, strings.TrimSpace(name)) {
+			paintKits = append(paintKits, paintKit)
+		}
+	}
+	return paintKits
+}
+
+// Skin looks up a paint kit as it appears on a weapon.
+func (schema *Schema) Skin(defIndex, paintIndex int) (Skin, bool) {
+	skin, ok := schema.skins[[2]int{defIndex, paintIndex}]
+	return skin, ok
+}
+
+// SkinsWithPaint returns every skin carrying a paint with the display name,
+// ordered by weapon DefIndex.
+func (schema *Schema) SkinsWithPaint(name string) []Skin {
+	paintIndexes := map[int]bool{}
+	for _, paintKit := range schema.PaintKitsNamed(name) {
+		paintIndexes[paintKit.PaintIndex] = true
+	}
+
+	skins := []Skin{}
+	for _, skin := range schema.Skins {
+		if paintIndexes[skin.PaintIndex] {
+			skins = append(skins, skin)
+		}
+	}
+	sort.Slice(skins, func(i, j int) bool {
+		if skins[i].DefIndex != skins[j].DefIndex {
+			return skins[i].DefIndex < skins[j].DefIndex
+		}
+		return skins[i].PaintIndex < skins[j].PaintIndex
+	})
+	return skins
+}
+
+// MarketName is the market name of a skin without its wear or prefixes,
+// e.g. "AK-47 | Case Hardened", or "" if the weapon or paint is unknown.
+func (schema *Schema) MarketName(defIndex, paintIndex int) string {
+	weapon, ok := schema.Weapon(defIndex)
+	if !ok {
+		return ""
+	}
+	if paintIndex == 0 {
+		return weapon.Name
+	}
+	paintKit, ok := schema.PaintKit(paintIndex)
+	if !ok {
+		return ""
+	}
+	return weapon.Name + " | " + paintKit.Name
+}
+
+// RarityName is the display name of a rarity, e.g. "Covert".
+func RarityName(rarity int) string {
+	return rarityNames[rarity]
+}
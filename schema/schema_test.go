@@ -0,0 +1,210 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func generateTestSchema(t *testing.T) *Schema {
+	t.Helper()
+
+	itemsGame, err := os.Open("testdata/items_game.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer itemsGame.Close()
+
+	language, err := os.Open("testdata/csgo_english.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer language.Close()
+
+	schema, err := Generate(itemsGame, language)
+	if err != nil {
+		t.Fatalf("failed to generate schema: %s", err)
+	}
+	return schema
+}
+
+func TestGenerate(t *testing.T) {
+	schema := generateTestSchema(t)
+
+	weapon, ok := schema.Weapon(7)
+	if !ok || weapon != (Weapon{DefIndex: 7, ClassName: "weapon_ak47", Name: "AK-47", Kind: KindWeapon}) {
+		t.Errorf("got %+v for the AK-47", weapon)
+	}
+
+	weapon, ok = schema.Weapon(508)
+	if !ok || weapon.Name != "M9 Bayonet" || weapon.Kind != KindKnife {
+		t.Errorf("got %+v for the M9 Bayonet", weapon)
+	}
+
+	weapon, ok = schema.Weapon(5030)
+	if !ok || weapon.Name != "Sport Gloves" || weapon.Kind != KindGloves {
+		t.Errorf("got %+v for the Sport Gloves", weapon)
+	}
+
+	// The default knife cannot be traded.
+	if _, ok := schema.Weapon(42); ok {
+		t.Error("expected the default knife to be left out")
+	}
+
+	paintKit, ok := schema.PaintKit(44)
+	if !ok || paintKit.Name != "Case Hardened" || paintKit.WearMin != 0 || paintKit.WearMax != 1 {
+		t.Errorf("got %+v for Case Hardened", paintKit)
+	}
+
+	// Paint kits without their own wear caps take the default paint kit's.
+	paintKit, ok = schema.PaintKit(12)
+	if !ok || paintKit.WearMin != 0.06 || paintKit.WearMax != 0.8 {
+		t.Errorf("got %+v for Crimson Web", paintKit)
+	}
+
+	skin, ok := schema.Skin(7, 44)
+	if !ok || skin.Rarity != RarityClassified || skin.Collection != "The Arms Deal Collection" {
+		t.Errorf("got %+v for the AK-47 Case Hardened", skin)
+	}
+
+	skin, ok = schema.Skin(508, 44)
+	if !ok || skin.Rarity != RarityCovert {
+		t.Errorf("got %+v for the M9 Bayonet Case Hardened", skin)
+	}
+
+	// The M9 Bayonet icons must not be read as a Bayonet paint.
+	if _, ok := schema.Skin(500, 44); !ok {
+		t.Error("expected a Bayonet Case Hardened")
+	}
+}
+
+func TestSkinsWithPaint(t *testing.T) {
+	schema := generateTestSchema(t)
+
+	defIndexes := []int{}
+	for _, skin := range schema.SkinsWithPaint("case hardened") {
+		defIndexes = append(defIndexes, skin.DefIndex)
+	}
+
+	if !reflect.DeepEqual(defIndexes[:4], []int{3, 7, 17, 500}) {
+		t.Errorf("got %v, want the Five-SeveN, AK-47, MAC-10 then the knives", defIndexes)
+	}
+	if len(defIndexes) != 23 {
+		t.Errorf("got %d skins, want 23", len(defIndexes))
+	}
+}
+
+func TestMarketName(t *testing.T) {
+	schema := generateTestSchema(t)
+
+	for _, test := range []struct {
+		defIndex, paintIndex int
+		want                 string
+	}{
+		{7, 44, "AK-47 | Case Hardened"},
+		{512, 44, "Falchion Knife | Case Hardened"},
+		{507, 0, "Karambit"},
+		{7, 9999, ""},
+		{9999, 44, ""},
+	} {
+		if got := schema.MarketName(test.defIndex, test.paintIndex); got != test.want {
+			t.Errorf("%d_%d: got %q, want %q", test.defIndex, test.paintIndex, got, test.want)
+		}
+	}
+}
+
+func TestLoadGenerated(t *testing.T) {
+	generated := generateTestSchema(t)
+
+	// Written the way the generator writes schema.json.
+	schemaJSON, err := json.MarshalIndent(generated, "", "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(schemaJSON)
+	if err != nil {
+		t.Fatalf("failed to load the generated schema: %s", err)
+	}
+
+	if !reflect.DeepEqual(loaded.Weapons, generated.Weapons) || !reflect.DeepEqual(loaded.PaintKits, generated.PaintKits) || !reflect.DeepEqual(loaded.Skins, generated.Skins) {
+		t.Error("the generated schema changed on the way through JSON")
+	}
+	if got := loaded.MarketName(512, 44); got != "Falchion Knife | Case Hardened" {
+		t.Errorf("got %q from the loaded schema, want the Falchion Knife | Case Hardened", got)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	bundled, err := Default()
+	if err != nil {
+		t.Fatalf("failed to load the bundled schema: %s", err)
+	}
+	if len(bundled.Weapons) == 0 || len(bundled.PaintKits) == 0 || len(bundled.Skins) == 0 {
+		t.Fatalf("got %d weapons, %d paint kits and %d skins in the bundled schema", len(bundled.Weapons), len(bundled.PaintKits), len(bundled.Skins))
+	}
+
+	for _, skin := range bundled.Skins {
+		if bundled.MarketName(skin.DefIndex, skin.PaintIndex) == "" {
+			t.Errorf("the bundled skin %d_%d has an unknown weapon or paint kit", skin.DefIndex, skin.PaintIndex)
+		}
+	}
+}
+
+func TestParseKeyValues(t *testing.T) {
+	document := `// comment
+"root"
+{
+	"key"		"value" [$WIN32]
+	bare		"quoted \"escape\""
+	"block"
+	{
+		"nested"	"1"
+	}
+	"block"
+	{
+		"nested"	"2"
+	}
+}`
+
+	root, err := parseKeyValues(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	node := root.children("root")[0]
+	if node.value("KEY") != "value" {
+		t.Errorf("got %q for key", node.value("key"))
+	}
+	if node.value("bare") != `quoted "escape"` {
+		t.Errorf("got %q for bare", node.value("bare"))
+	}
+
+	merged := node.merged("block")
+	if len(merged) != 2 || merged[0].Value != "1" || merged[1].Value != "2" {
+		t.Errorf("got %+v for the merged blocks", merged)
+	}
+
+	for _, invalid := range []string{`"a" {`, `"a" "b" }`, `"a"`, `"a" "unterminated`} {
+		if _, err := parseKeyValues(strings.NewReader(invalid)); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+}
+
+func TestParseKeyValuesUTF16(t *testing.T) {
+	// "a" "★" encoded as UTF-16LE with a byte order mark.
+	document := []byte{0xFF, 0xFE}
+	for _, r := range `"a" "★"` {
+		document = append(document, byte(r), byte(r>>8))
+	}
+
+	root, err := parseKeyValues(strings.NewReader(string(document)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if root.value("a") != "★" {
+		t.Errorf("got %q, want ★", root.value("a"))
+	}
+}
@@ -0,0 +1,241 @@
+package schema
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// kvNode is a key in a Valve KeyValues (VDF) document, such as items_game.txt,
+// holding either a string value or a block of child keys.
+type kvNode struct {
+	Key      string
+	Value    string
+	Children []*kvNode
+}
+
+// children returns every child block or value with the key. Keys are
+// matched case-insensitively, and the same key may appear more than once,
+// as "items" and "paint_kits" do in items_game.txt.
+func (node *kvNode) children(key string) []*kvNode {
+	matches := []*kvNode{}
+	for _, child := range node.Children {
+		if strings.EqualFold(child.Key, key) {
+			matches = append(matches, child)
+		}
+	}
+	return matches
+}
+
+// merged returns the children of every block with the key as one list.
+func (node *kvNode) merged(key string) []*kvNode {
+	merged := []*kvNode{}
+	for _, block := range node.children(key) {
+		merged = append(merged, block.Children...)
+	}
+	return merged
+}
+
+// value returns the first string value of the key, or "" if there is none.
+func (node *kvNode) value(key string) string {
+	for _, child := range node.children(key) {
+		if child.Children == nil {
+			return child.Value
+		}
+	}
+	return ""
+}
+
+// parseKeyValues reads a KeyValues document into a root node whose children
+// are the top level keys. UTF-16 files, as the game's language files are, are
+// converted to UTF-8 first.
+func parseKeyValues(r io.Reader) (*kvNode, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = decodeUTF16(data)
+
+	scanner := &kvScanner{reader: bufio.NewReader(bytes.NewReader(data)), line: 1}
+	root := &kvNode{}
+	err = scanner.parseBlock(root, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// decodeUTF16 converts UTF-16LE data with a byte order mark to UTF-8, and
+// strips a UTF-8 byte order mark, leaving anything else untouched.
+func decodeUTF16(data []byte) []byte {
+	if bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}) {
+		return data[3:]
+	}
+	if !bytes.HasPrefix(data, []byte{0xFF, 0xFE}) {
+		return data
+	}
+
+	data = data[2:]
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+	}
+	return []byte(string(utf16.Decode(units)))
+}
+
+// kvScanner tokenises a KeyValues document.
+type kvScanner struct {
+	reader *bufio.Reader
+	line   int
+}
+
+// kvToken kinds.
+const (
+	kvString = iota
+	kvOpen
+	kvClose
+	kvEOF
+)
+
+// parseBlock reads keys into the node until the closing brace, or the end of
+// the document for the root.
+func (scanner *kvScanner) parseBlock(node *kvNode, isRoot bool) error {
+	for {
+		kind, key, err := scanner.next()
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case kvEOF:
+			if !isRoot {
+				return fmt.Errorf("line %d: unexpected end of file, missing }", scanner.line)
+			}
+			return nil
+		case kvClose:
+			if isRoot {
+				return fmt.Errorf("line %d: unexpected }", scanner.line)
+			}
+			return nil
+		case kvOpen:
+			return fmt.Errorf("line %d: unexpected { without a key", scanner.line)
+		}
+
+		kind, value, err := scanner.next()
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case kvString:
+			node.Children = append(node.Children, &kvNode{Key: key, Value: value})
+		case kvOpen:
+			child := &kvNode{Key: key, Children: []*kvNode{}}
+			err = scanner.parseBlock(child, false)
+			if err != nil {
+				return err
+			}
+			node.Children = append(node.Children, child)
+		default:
+			return fmt.Errorf("line %d: key %q has no value", scanner.line, key)
+		}
+	}
+}
+
+// next returns the next token, skipping whitespace, comments and platform
+// conditionals such as [$WIN32].
+func (scanner *kvScanner) next() (int, string, error) {
+	for {
+		r, _, err := scanner.reader.ReadRune()
+		if errors.Is(err, io.EOF) {
+			return kvEOF, "", nil
+		}
+		if err != nil {
+			return 0, "", err
+		}
+
+		switch {
+		case r == '\n':
+			scanner.line++
+		case r == ' ' || r == '\t' || r == '\r':
+		case r == '{':
+			return kvOpen, "", nil
+		case r == '}':
+			return kvClose, "", nil
+		case r == '"':
+			value, err := scanner.quoted()
+			return kvString, value, err
+		case r == '/':
+			next, _, _ := scanner.reader.ReadRune()
+			if next != '/' {
+				return 0, "", fmt.Errorf("line %d: unexpected /", scanner.line)
+			}
+			_, err := scanner.reader.ReadString('\n')
+			scanner.line++
+			if errors.Is(err, io.EOF) {
+				return kvEOF, "", nil
+			}
+		case r == '[':
+			_, err := scanner.reader.ReadString(']')
+			if err != nil {
+				return 0, "", fmt.Errorf("line %d: unterminated conditional", scanner.line)
+			}
+		default:
+			scanner.reader.UnreadRune()
+			return kvString, scanner.unquoted(), nil
+		}
+	}
+}
+
+// quoted reads a quoted string up to its closing quote, handling escapes.
+func (scanner *kvScanner) quoted() (string, error) {
+	value := strings.Builder{}
+	for {
+		r, _, err := scanner.reader.ReadRune()
+		if err != nil {
+			return "", fmt.Errorf("line %d: unterminated string", scanner.line)
+		}
+
+		switch r {
+		case '"':
+			return value.String(), nil
+		case '\n':
+			scanner.line++
+		case '\\':
+			escaped, _, err := scanner.reader.ReadRune()
+			if err != nil {
+				return "", fmt.Errorf("line %d: unterminated string", scanner.line)
+			}
+			switch escaped {
+			case 'n':
+				r = '\n'
+			case 't':
+				r = '\t'
+			default:
+				r = escaped
+			}
+		}
+		value.WriteRune(r)
+	}
+}
+
+// unquoted reads a bare token up to the next whitespace or brace.
+func (scanner *kvScanner) unquoted() string {
+	value := strings.Builder{}
+	for {
+		r, _, err := scanner.reader.ReadRune()
+		if err != nil {
+			return value.String()
+		}
+		if strings.ContainsRune(" \t\r\n{}\"", r) {
+			scanner.reader.UnreadRune()
+			return value.String()
+		}
+		value.WriteRune(r)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"eiffel65/steam"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// runSearch searches the market for item names, printing the results or
+// scanning the listings of every one of them.
+func runSearch(flags *flag.FlagSet, args []string) error {
+	clientFlags := clientOptions{}
+	clientFlags.register(flags)
+	alertFlags := alertOptions{}
+	alertFlags.register(flags)
+	outputFlags := outputOptions{}
+	outputFlags.register(flags)
+	profitFlags := profitOptions{}
+	profitFlags.register(flags)
+	historyFlags := historyOptions{}
+	historyFlags.register(flags)
+	wearTier := flags.String("w", "", "only find these wears (1-5 Factory New to Battle-Scarred, a list like 1,2 or all)")
+	listings := flags.Int("l", defaultListingCount, "how many search results, and listings of each when scanning")
+	scanResults := flags.Bool("scan", false, "scan the listings of every search result")
+	flags.Parse(args)
+
+	query := strings.Join(flags.Args(), " ")
+	if query == "" {
+		return fmt.Errorf("please specify a search query")
+	}
+
+	settings, err := clientFlags.load()
+	if err != nil {
+		return err
+	}
+
+	steamClient, err := clientFlags.newClient(settings, true)
+	if err != nil {
+		return err
+	}
+
+	filters, err := searchFilters(*wearTier, *listings)
+	if err != nil {
+		return err
+	}
+
+	searchPayload, err := steamClient.Search(query, filters)
+	if err != nil {
+		return fmt.Errorf("failed to search the market: %s", err)
+	}
+
+	if !*scanResults {
+		return printJSON(searchPayload.Results)
+	}
+
+	rules, err := alertFlags.loadRules(settings)
+	if err != nil {
+		return err
+	}
+
+	notifier, err := alertFlags.alertSinks(settings, rules)
+	if err != nil {
+		return err
+	}
+
+	printer, err := outputFlags.printer()
+	if err != nil {
+		return err
+	}
+
+	db, err := historyFlags.open(settings)
+	if err != nil {
+		return err
+	}
+	if db != nil {
+		defer db.Close()
+	}
+
+	assetList := scanSearchResults(steamClient, searchPayload.Results, *listings)
+	recordScan(db, *assetList, time.Now())
+	return reportScan(steamClient, rules, profitFlags, notifier, printer, assetList)
+}
+
+// searchFilters limits a search to the given wears, if any.
+func searchFilters(wearTier string, count int) (steam.SearchFilters, error) {
+	filters := steam.SearchFilters{Count: count}
+	if wearTier == "" {
+		return filters, nil
+	}
+
+	wearTiers, err := steam.ParseWearTiers(wearTier)
+	if err != nil {
+		return filters, err
+	}
+	for _, variant := range steam.NewVariants(wearTiers, false, false) {
+		if variant.Wear != "" {
+			filters.Exterior = append(filters.Exterior, variant.Wear)
+		}
+	}
+
+	return filters, nil
+}
+
+// scanSearchResults fetches the listings of every search result into one list.
+func scanSearchResults(steamClient *steam.Client, results []steam.SearchResult, listings int) *[]steam.SimpleAsset {
+	assetList := []steam.SimpleAsset{}
+	for _, result := range results {
+		assets, err := steamClient.NewAssetFromMarketName(result.HashName, listings)
+		if err != nil {
+			slog.Warn("failed to get asset listings", "name", result.HashName, "error", err)
+			continue
+		}
+		if assets != nil {
+			assetList = append(assetList, *assets...)
+		}
+	}
+	return &assetList
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"eiffel65/history"
+	"eiffel65/metrics"
+	"eiffel65/notify"
+	"eiffel65/server"
+	"eiffel65/steam"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultServeAddr     = "localhost:8065"
+	defaultCacheTTL      = time.Minute
+	defaultServeRate     = 30
+	serveShutdownTimeout = 10 * time.Second
+)
+
+// runServe answers listings, inspect, price and alert lookups over a JSON
+// API and serves the dashboard until interrupted, optionally watching for new
+// listings to show on it live.
+func runServe(flags *flag.FlagSet, args []string) error {
+	clientFlags := clientOptions{}
+	clientFlags.register(flags)
+	itemFlags := itemOptions{}
+	itemFlags.register(flags)
+	alertFlags := alertOptions{}
+	alertFlags.register(flags)
+	profitFlags := profitOptions{}
+	profitFlags.register(flags)
+	historyFlags := historyOptions{}
+	historyFlags.register(flags)
+	addr := flags.String("addr", defaultServeAddr, "the address to listen on")
+	cacheTTL := flags.Duration("cache", defaultCacheTTL, "how long to cache lookups, 0 to not cache them")
+	rateLimit := flags.Int("rate", defaultServeRate, "how many uncached lookups a minute to allow across every request, 0 for no limit")
+	watchListings := flags.Bool("watch", false, "keep rescanning the item, family or watchlist, showing new listings on the dashboard live")
+	interval := flags.Duration("interval", defaultWatchInterval, "how often to rescan with -watch, e.g. 30s or 5m")
+	flags.Parse(args)
+
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", flags.Args())
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("the -interval must be positive")
+	}
+
+	settings, err := clientFlags.load()
+	if err != nil {
+		return err
+	}
+
+	rules, err := alertFlags.loadRules(settings)
+	if err != nil {
+		return err
+	}
+
+	steamClient, err := clientFlags.newClient(settings, false)
+	if err != nil {
+		return err
+	}
+
+	notifier, err := alertFlags.alertSinks(settings, rules)
+	if err != nil {
+		return err
+	}
+
+	handler := server.New(steamClient, server.Config{
+		CacheTTL:  *cacheTTL,
+		RateLimit: *rateLimit,
+		Match: func(assetList []steam.SimpleAsset) []steam.RuleMatch {
+			return findMatches(steamClient, rules, profitFlags, assetList, assetList)
+		},
+		Notifier: notifier,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *watchListings {
+		db, err := historyFlags.open(settings)
+		if err != nil {
+			return err
+		}
+		if db != nil {
+			defer db.Close()
+		}
+
+		scan, err := itemFlags.scanner(flags, steamClient, settings)
+		if err != nil {
+			return err
+		}
+		go serveWatch(ctx, steamClient, rules, profitFlags, scan, notify.Multi{notifier, handler}, handler, db, *interval)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/", handler)
+
+	httpServer := &http.Server{Addr: *addr, Handler: mux}
+	httpServer.RegisterOnShutdown(handler.Close)
+	return serve(ctx, httpServer)
+}
+
+// serveWatch rescans on the interval until the context is done, publishing
+// every scan to the dashboard and recording it in the history, including
+// those with nothing new, so the listings that are gone drop off both.
+func serveWatch(ctx context.Context, steamClient *steam.Client, rules []steam.Rule, profit profitOptions, scan func() (*[]steam.SimpleAsset, []string, error), notifier notify.Notifier, handler *server.Server, db *history.DB, interval time.Duration) {
+	slog.Info("watching for new listings", "interval", interval)
+
+	watcher := steam.NewWatcher(interval)
+	watcher.Logger = steamClient.Logger
+	watcher.Watch(ctx, scan, func(report steam.WatchReport) {
+		recordScan(db, report.Listings, report.Time)
+
+		matches := findMatches(steamClient, rules, profit, report.NewListings, report.Listings)
+		sendAlerts(notifier, matches)
+
+		slog.Info("scanned", "scan", report.Scan, "listings", len(report.Listings), "new_listings", len(report.NewListings), "highlights", len(matches))
+
+		handler.Publish(report, matches)
+	})
+}
+
+// serve listens until the context is done, then lets the requests in flight
+// finish.
+func serve(ctx context.Context, httpServer *http.Server) error {
+	errs := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "url", "http://"+httpServer.Addr)
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	err := httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"eiffel65/metrics"
+)
+
+// cache keeps the results of lookups for a while, so repeated requests do not
+// go back to Steam. Concurrent requests for the same key share one lookup.
+type cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is a lookup that is either in flight or done.
+type cacheEntry struct {
+	done    chan struct{}
+	value   interface{}
+	err     error
+	expires time.Time
+}
+
+// newCache creates a cache keeping results for ttl, or not at all if it is
+// not positive.
+func newCache(ttl time.Duration) *cache {
+	return &cache{ttl: ttl, entries: map[string]*cacheEntry{}}
+}
+
+// get returns the cached value for the key, looking it up with fetch if it
+// is missing or expired. Errors are not cached.
+func (c *cache) get(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	now := time.Now()
+	entry, ok := c.entries[key]
+	if ok {
+		select {
+		case <-entry.done:
+			if now.After(entry.expires) {
+				ok = false
+			}
+		default:
+		}
+	}
+	metrics.CacheLookup(ok)
+	if ok {
+		c.mu.Unlock()
+		<-entry.done
+		return entry.value, entry.err
+	}
+
+	c.prune(now)
+	entry = &cacheEntry{done: make(chan struct{})}
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	entry.value, entry.err = fetch()
+
+	c.mu.Lock()
+	entry.expires = time.Now().Add(c.ttl)
+	if entry.err != nil || c.ttl <= 0 {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	close(entry.done)
+
+	return entry.value, entry.err
+}
+
+// prune drops the expired entries. The lock must be held.
+func (c *cache) prune(now time.Time) {
+	for key, entry := range c.entries {
+		select {
+		case <-entry.done:
+			if now.After(entry.expires) {
+				delete(c.entries, key)
+			}
+		default:
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"eiffel65/output"
+	"eiffel65/steam"
+)
+
+const (
+	// subscriberBuffer is how many updates a slow dashboard can fall behind
+	// before updates to it are dropped.
+	subscriberBuffer  int           = 16
+	heartbeatInterval time.Duration = 30 * time.Second
+)
+
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// Update is a watch scan as sent to the dashboard: the new listings and the
+// keys of those gone since the last scan.
+type Update struct {
+	Scan     int              `json:"scan"`
+	Time     time.Time        `json:"time"`
+	Listings []output.Listing `json:"listings"`
+	Removed  []string         `json:"removed,omitempty"`
+}
+
+// dashboard serves the web UI.
+func dashboard() http.Handler {
+	files, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServer(http.FS(files))
+}
+
+// Publish passes a watch scan on to the dashboards, keeping the listings
+// still on the market for /watch. It is called for every scan, so listings
+// that are gone are removed even when nothing new was posted. Listings seen
+// in earlier scans keep the scores and highlights they were first found
+// with.
+func (server *Server) Publish(report steam.WatchReport, matches []steam.RuleMatch) {
+	update := Update{
+		Scan:     report.Scan,
+		Time:     report.Time,
+		Listings: output.Listings(report.NewListings, matches),
+		Removed:  []string{},
+	}
+
+	current := map[string]bool{}
+	for _, listing := range output.Listings(report.Listings, nil) {
+		current[listing.Key()] = true
+	}
+
+	server.mu.Lock()
+	watched := []output.Listing{}
+	for _, listing := range server.watched {
+		if current[listing.Key()] {
+			watched = append(watched, listing)
+		} else {
+			update.Removed = append(update.Removed, listing.Key())
+		}
+	}
+	server.watched = append(watched, update.Listings...)
+	server.watching = true
+
+	for subscriber := range server.subscribers {
+		select {
+		case subscriber <- update:
+		default:
+			server.logger().Warn("dropped scan for a slow dashboard", "scan", update.Scan)
+		}
+	}
+	server.mu.Unlock()
+}
+
+// Close disconnects the dashboards following the watch, so the server can
+// shut down.
+func (server *Server) Close() {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	select {
+	case <-server.closed:
+	default:
+		close(server.closed)
+	}
+}
+
+// handleWatch lists the listings found by watch mode that are still on the
+// market.
+func (server *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	watching := server.watching
+	watched := append([]output.Listing{}, server.watched...)
+	server.mu.Unlock()
+
+	if !watching {
+		writeError(w, http.StatusNotFound, fmt.Errorf("nothing watched yet, serve with -watch to follow new listings"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, watched)
+}
+
+// handleEvents streams each watch scan to a dashboard as a server-sent
+// event until it disconnects.
+func (server *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	updates := make(chan Update, subscriberBuffer)
+	server.mu.Lock()
+	server.subscribers[updates] = true
+	server.mu.Unlock()
+	defer func() {
+		server.mu.Lock()
+		delete(server.subscribers, updates)
+		server.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case update := <-updates:
+			updateJSON, err := json.Marshal(update)
+			if err != nil {
+				requestLogger(r).Error("failed to marshal scan", "scan", update.Scan, "error", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: update\ndata: %s\n\n", updateJSON)
+		case <-heartbeat.C:
+			// A comment keeps proxies from closing an idle stream.
+			fmt.Fprint(w, ": ping\n\n")
+		case <-r.Context().Done():
+			return
+		case <-server.closed:
+			return
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,253 @@
+"use strict";
+
+// The listings shown, either the watched listings kept up to date over
+// server-sent events or the result of a scan from the search form.
+let listings = [];
+let watching = false;
+let sortKey = "";
+let sortDesc = false;
+const newKeys = new Set();
+
+const statusElement = document.getElementById("status");
+const tableBody = document.getElementById("listings");
+const summary = document.getElementById("summary");
+const searchForm = document.getElementById("search");
+const filterForm = document.getElementById("filters");
+
+// key identifies a listing the way the server does.
+function key(listing) {
+	return listing.listing_id || listing.id;
+}
+
+function setStatus(text, kind) {
+	statusElement.textContent = text;
+	statusElement.className = "status " + (kind || "");
+}
+
+// sortValue is the value a listing is sorted by, with missing values last.
+function sortValue(listing) {
+	switch (sortKey) {
+	case "name":
+		return listing.name || "";
+	case "float":
+		return listing.float && listing.float.floatvalue ? listing.float.floatvalue : Infinity;
+	case "seed":
+		return listing.float && listing.float.paintseed ? listing.float.paintseed : Infinity;
+	case "tier":
+		return listing.rarity_tier || Infinity;
+	case "price":
+		return listing.listing_total_price ? parseFloat(listing.listing_total_price) : Infinity;
+	case "deal":
+		// The best deals come first.
+		return listing.deal ? -listing.deal.score : Infinity;
+	}
+	return 0;
+}
+
+function compare(a, b) {
+	const x = sortValue(a);
+	const y = sortValue(b);
+	let order = 0;
+	if (x < y) {
+		order = -1;
+	} else if (x > y) {
+		order = 1;
+	}
+	if (sortDesc) {
+		order = -order;
+	}
+	return order || String(key(a)).localeCompare(String(key(b)));
+}
+
+// filtered applies the filter form to the listings.
+function filtered() {
+	const filters = new FormData(filterForm);
+	const text = (filters.get("text") || "").toLowerCase();
+	const minFloat = parseFloat(filters.get("minFloat"));
+	const maxFloat = parseFloat(filters.get("maxFloat"));
+	const maxTier = parseInt(filters.get("maxTier"), 10);
+	const seeds = (filters.get("seeds") || "").split(",")
+		.map((seed) => parseInt(seed, 10))
+		.filter((seed) => !isNaN(seed));
+	const highlightedOnly = filters.get("highlighted") !== null;
+
+	return listings.filter((listing) => {
+		const highlights = listing.highlights || [];
+		const floatValue = listing.float ? listing.float.floatvalue : undefined;
+		const paintSeed = listing.float ? listing.float.paintseed : undefined;
+
+		if (text && !(listing.name || "").toLowerCase().includes(text) &&
+			!highlights.some((highlight) => highlight.toLowerCase().includes(text))) {
+			return false;
+		}
+		if (!isNaN(minFloat) && !(floatValue >= minFloat)) {
+			return false;
+		}
+		if (!isNaN(maxFloat) && !(floatValue <= maxFloat)) {
+			return false;
+		}
+		if (seeds.length > 0 && !seeds.includes(paintSeed)) {
+			return false;
+		}
+		if (!isNaN(maxTier) && !(listing.rarity_tier && listing.rarity_tier <= maxTier)) {
+			return false;
+		}
+		if (highlightedOnly && highlights.length === 0) {
+			return false;
+		}
+		return true;
+	});
+}
+
+function cell(row, text, className) {
+	const td = row.insertCell();
+	td.textContent = text;
+	if (className) {
+		td.className = className;
+	}
+	return td;
+}
+
+function render() {
+	const shown = filtered();
+	if (sortKey) {
+		shown.sort(compare);
+	}
+
+	tableBody.replaceChildren();
+	for (const listing of shown) {
+		const row = tableBody.insertRow();
+		const highlights = listing.highlights || [];
+		row.classList.toggle("highlighted", highlights.length > 0);
+		row.classList.toggle("new", newKeys.has(key(listing)));
+
+		const screenshot = row.insertCell();
+		if (listing.screenshot_url) {
+			const link = document.createElement("a");
+			link.href = listing.screenshot_url;
+			link.target = "_blank";
+			link.rel = "noopener";
+			const img = document.createElement("img");
+			img.src = listing.screenshot_url;
+			img.alt = "";
+			img.loading = "lazy";
+			img.className = "screenshot";
+			link.append(img);
+			screenshot.append(link);
+		}
+
+		const name = cell(row, listing.name || "");
+		if (listing.variant) {
+			const variant = document.createElement("div");
+			variant.className = "variant";
+			variant.textContent = listing.variant;
+			name.append(variant);
+		}
+
+		cell(row, listing.float && listing.float.floatvalue ? listing.float.floatvalue.toFixed(6) : "-", "number");
+		cell(row, listing.float && listing.float.paintseed ? listing.float.paintseed : "-", "number");
+		cell(row, listing.rarity_tier || "-", "number");
+		cell(row, listing.listing_total_price || "-", "number");
+		cell(row, listing.deal ? listing.deal.score.toFixed(1) : "-", "number");
+
+		const highlightCell = row.insertCell();
+		for (const highlight of highlights) {
+			const tag = document.createElement("span");
+			tag.className = "highlight";
+			tag.textContent = highlight;
+			highlightCell.append(tag);
+		}
+
+		const inspect = row.insertCell();
+		if (listing.inspect_url) {
+			const link = document.createElement("a");
+			link.href = listing.inspect_url;
+			link.textContent = "Inspect";
+			inspect.append(link);
+		}
+	}
+
+	summary.textContent = `${shown.length} of ${listings.length} listings` +
+		(watching ? ", following watch mode" : "");
+}
+
+async function fetchJSON(path) {
+	const response = await fetch(path);
+	const body = await response.json();
+	if (!response.ok) {
+		throw new Error(body.error || response.statusText);
+	}
+	return body;
+}
+
+// showWatch shows the watched listings, if the server is watching.
+async function showWatch() {
+	try {
+		listings = await fetchJSON("watch");
+		watching = true;
+		newKeys.clear();
+	} catch (err) {
+		watching = false;
+		setStatus(err.message);
+	}
+	render();
+}
+
+// follow applies each watch scan as it arrives.
+function follow() {
+	const events = new EventSource("events");
+	events.onopen = () => setStatus("connected");
+	events.onerror = () => setStatus("disconnected, retrying", "error");
+	events.addEventListener("update", (event) => {
+		const update = JSON.parse(event.data);
+		setStatus(`live, scan ${update.scan} at ${new Date(update.time).toLocaleTimeString()}`, "live");
+		if (!watching) {
+			return;
+		}
+
+		const removed = new Set(update.removed || []);
+		listings = listings.filter((listing) => !removed.has(key(listing)));
+		for (const listing of update.listings || []) {
+			newKeys.add(key(listing));
+			listings.push(listing);
+		}
+		render();
+	});
+}
+
+searchForm.addEventListener("submit", async (event) => {
+	event.preventDefault();
+	const params = new URLSearchParams(new FormData(searchForm));
+	summary.textContent = "scanning…";
+	try {
+		listings = await fetchJSON("listings?" + params);
+		watching = false;
+		newKeys.clear();
+		render();
+	} catch (err) {
+		summary.textContent = err.message;
+	}
+});
+
+document.getElementById("show-watch").addEventListener("click", showWatch);
+filterForm.addEventListener("input", render);
+filterForm.addEventListener("submit", (event) => event.preventDefault());
+
+for (const th of document.querySelectorAll("th[data-sort]")) {
+	th.addEventListener("click", () => {
+		if (sortKey === th.dataset.sort) {
+			sortDesc = !sortDesc;
+		} else {
+			sortKey = th.dataset.sort;
+			sortDesc = false;
+		}
+		for (const other of document.querySelectorAll("th[data-sort]")) {
+			other.classList.remove("asc", "desc");
+		}
+		th.classList.add(sortDesc ? "desc" : "asc");
+		render();
+	});
+}
+
+showWatch();
+follow();
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"eiffel65/float"
+	"eiffel65/output"
+	"eiffel65/steam"
+)
+
+var (
+	rareListing   = steam.SimpleAsset{ID: "11", ListingID: "101", Float: float.AssetFloat{DefIndex: 7, PaintSeed: 661}}
+	commonListing = steam.SimpleAsset{ID: "12", ListingID: "102", Float: float.AssetFloat{DefIndex: 7, PaintSeed: 12}}
+	laterListing  = steam.SimpleAsset{ID: "13", ListingID: "103", Float: float.AssetFloat{DefIndex: 7, PaintSeed: 20}}
+)
+
+func TestDashboard(t *testing.T) {
+	server := New(steam.NewClient(""), Config{})
+
+	recorder := get(t, server, "/", nil)
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "<title>eiffel65</title>") {
+		t.Errorf("got %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := get(t, server, "/app.js", nil); recorder.Code != http.StatusOK {
+		t.Errorf("app.js: got %d", recorder.Code)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	server := New(steam.NewClient(""), Config{})
+
+	if recorder := get(t, server, "/watch", nil); recorder.Code != http.StatusNotFound {
+		t.Errorf("got %d before watching, want %d", recorder.Code, http.StatusNotFound)
+	}
+
+	first := []steam.SimpleAsset{rareListing, commonListing}
+	server.Publish(steam.WatchReport{Scan: 1, NewListings: first, Listings: first},
+		[]steam.RuleMatch{{Rule: "rare pattern", Asset: rareListing}})
+
+	// The rare listing sells and a new one is posted, while the common one
+	// keeps its place.
+	second := []steam.SimpleAsset{commonListing, laterListing}
+	server.Publish(steam.WatchReport{Scan: 2, NewListings: second[1:], Listings: second}, nil)
+
+	listings := []output.Listing{}
+	get(t, server, "/watch", &listings)
+	if len(listings) != 2 || listings[0].Key() != "102" || listings[1].Key() != "103" {
+		t.Errorf("got %+v", listings)
+	}
+
+	// A scan where a listing only sold, with nothing new, still removes it.
+	third := []steam.SimpleAsset{laterListing}
+	server.Publish(steam.WatchReport{Scan: 3, NewListings: []steam.SimpleAsset{}, Listings: third}, nil)
+
+	listings = []output.Listing{}
+	get(t, server, "/watch", &listings)
+	if len(listings) != 1 || listings[0].Key() != "103" {
+		t.Errorf("got %+v after a scan without new listings", listings)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	server := New(steam.NewClient(""), Config{})
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	defer server.Close()
+
+	response, err := http.Get(httpServer.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("got content type %q", contentType)
+	}
+
+	// Wait for the stream to subscribe before publishing.
+	reader := bufio.NewReader(response.Body)
+	line, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, ":") {
+		t.Fatalf("got %q, %v", line, err)
+	}
+
+	first := []steam.SimpleAsset{rareListing}
+	server.Publish(steam.WatchReport{Scan: 1, Time: time.Now(), NewListings: first, Listings: first},
+		[]steam.RuleMatch{{Rule: "rare pattern", Asset: rareListing}})
+
+	event := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(line, "event: ") {
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+		}
+		if strings.HasPrefix(line, "data: ") {
+			update := Update{}
+			err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &update)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if event != "update" || update.Scan != 1 || len(update.Listings) != 1 || !update.Listings[0].Highlighted() {
+				t.Errorf("got %s %+v", event, update)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket shared by every request, allowing a burst of
+// lookups and then one per interval.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// newLimiter creates a limiter allowing perMinute lookups a minute, or any
+// number if it is not positive.
+func newLimiter(perMinute int) *limiter {
+	if perMinute <= 0 {
+		return &limiter{}
+	}
+	return &limiter{
+		interval: time.Minute / time.Duration(perMinute),
+		burst:    float64(perMinute),
+		tokens:   float64(perMinute),
+	}
+}
+
+// allow takes a token if there is one, otherwise reporting how long until
+// there will be.
+func (l *limiter) allow(now time.Time) (bool, time.Duration) {
+	if l.interval <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false, time.Duration((1 - l.tokens) * float64(l.interval))
+	}
+	l.tokens--
+	return true, 0
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// requestIDHeader carries the ID of a request, taken from the caller if it
+// sent one so its logs can be matched with ours.
+const requestIDHeader string = "X-Request-ID"
+
+// loggerKey is the context key of the logger of a request.
+type loggerKey struct{}
+
+// statusRecorder remembers the status written to a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets /events stream through the recorder.
+func (recorder *statusRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap gives http.ResponseController the underlying response.
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+// logRequest tags the request with an ID, hands the handler a logger that
+// includes it and logs the request once it is done.
+func (server *Server) logRequest(w http.ResponseWriter, r *http.Request, handler http.Handler) {
+	requestID := r.Header.Get(requestIDHeader)
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	w.Header().Set(requestIDHeader, requestID)
+
+	logger := server.logger().With("request_id", requestID)
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	start := time.Now()
+	handler.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), loggerKey{}, logger)))
+
+	logger.Info("handled request",
+		"method", r.Method,
+		"path", r.URL.Path,
+		"query", r.URL.RawQuery,
+		"status", recorder.status,
+		"duration", time.Since(start))
+}
+
+// requestLogger is the logger of a request, tagged with its ID.
+func requestLogger(r *http.Request) *slog.Logger {
+	if logger, ok := r.Context().Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// newRequestID makes a random request ID.
+func newRequestID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,401 @@
+// Package server exposes the steam package over a small REST/JSON API, with
+// the lookups cached and rate limited across every request, along with a web
+// dashboard that follows watch mode live.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"eiffel65/metrics"
+	"eiffel65/notify"
+	"eiffel65/output"
+	"eiffel65/steam"
+)
+
+const (
+	defaultWearTier     string = "3"
+	defaultListingCount int    = 25
+	maxListingCount     int    = 100
+	maxAlerts           int    = 500
+)
+
+// Config tunes the server.
+type Config struct {
+	// CacheTTL is how long lookups are kept, zero to not cache them.
+	CacheTTL time.Duration
+	// RateLimit is how many uncached lookups a minute are allowed across
+	// every request, zero for no limit.
+	RateLimit int
+	// Match finds the highlights of a scan, pricing and scoring the listings
+	// as needed. Without it nothing is highlighted.
+	Match func(assetList []steam.SimpleAsset) []steam.RuleMatch
+	// Notifier is sent an alert for each highlight as well as them being kept
+	// for /alerts.
+	Notifier notify.Notifier
+	// Logger is given each request and the lookups that fail, slog.Default()
+	// if nil.
+	Logger *slog.Logger
+}
+
+// Alert is a highlight found by the server, with when it was found.
+type Alert struct {
+	Time time.Time `json:"time"`
+	notify.Alert
+}
+
+// Server answers the API requests.
+type Server struct {
+	client  *steam.Client
+	config  Config
+	cache   *cache
+	limiter *limiter
+	mux     *http.ServeMux
+
+	mu          sync.Mutex
+	alerts      []Alert
+	watching    bool
+	watched     []output.Listing
+	subscribers map[chan Update]bool
+	closed      chan struct{}
+}
+
+// scan is the cached result of a listings lookup.
+type scan struct {
+	assetList []steam.SimpleAsset
+	matches   []steam.RuleMatch
+}
+
+// rateLimitError is returned for a lookup over the rate limit.
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (err rateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", err.retryAfter.Round(time.Second))
+}
+
+// New creates a server looking items up with the client.
+func New(client *steam.Client, config Config) *Server {
+	server := &Server{
+		client:  client,
+		config:  config,
+		cache:   newCache(config.CacheTTL),
+		limiter: newLimiter(config.RateLimit),
+		mux:     http.NewServeMux(),
+
+		subscribers: map[chan Update]bool{},
+		closed:      make(chan struct{}),
+	}
+
+	server.mux.HandleFunc("/listings", onlyGet(server.handleListings))
+	server.mux.HandleFunc("/inspect", onlyGet(server.handleInspect))
+	server.mux.HandleFunc("/price", onlyGet(server.handlePrice))
+	server.mux.HandleFunc("/alerts", onlyGet(server.handleAlerts))
+	server.mux.HandleFunc("/watch", onlyGet(server.handleWatch))
+	server.mux.HandleFunc("/events", onlyGet(server.handleEvents))
+	server.mux.Handle("/", onlyGet(dashboard().ServeHTTP))
+
+	return server
+}
+
+// ServeHTTP routes a request to its handler, logging it with a request ID.
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.logRequest(w, r, server.mux)
+}
+
+// logger is the logger the server logs to.
+func (server *Server) logger() *slog.Logger {
+	if server.config.Logger != nil {
+		return server.config.Logger
+	}
+	return slog.Default()
+}
+
+// Notify keeps an alert for /alerts, dropping the oldest once there are too
+// many.
+func (server *Server) Notify(alert notify.Alert) error {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.alerts = append(server.alerts, Alert{Time: time.Now(), Alert: alert})
+	if len(server.alerts) > maxAlerts {
+		server.alerts = append([]Alert{}, server.alerts[len(server.alerts)-maxAlerts:]...)
+	}
+	return nil
+}
+
+// lookup returns the cached result for the key, calling fetch if there is
+// none and the rate limit allows it.
+func (server *Server) lookup(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	return server.cache.get(key, func() (interface{}, error) {
+		ok, retryAfter := server.limiter.allow(time.Now())
+		if !ok {
+			return nil, rateLimitError{retryAfter: retryAfter}
+		}
+		return fetch()
+	})
+}
+
+// handleListings scans the market listings of an item, e.g.
+// /listings?name=AK-47 | Case Hardened&wear=1,2&stattrak=both&sort=float.
+func (server *Server) handleListings(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	name := strings.TrimSpace(query.Get("name"))
+	if name == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing name"))
+		return
+	}
+
+	assetType, err := steam.ParseAssetType(valueOr(query.Get("type"), "weapon"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	wearTiers, err := steam.ParseWearTiers(valueOr(query.Get("wear"), defaultWearTier))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	statTrakOptions := []bool{false}
+	switch statTrak := strings.ToLower(query.Get("stattrak")); statTrak {
+	case "":
+	case "both":
+		statTrakOptions = []bool{false, true}
+	default:
+		isStatTrak, err := strconv.ParseBool(statTrak)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid stattrak %q, expected true, false or both", statTrak))
+			return
+		}
+		statTrakOptions = []bool{isStatTrak}
+	}
+
+	isSouvenir := false
+	if souvenir := query.Get("souvenir"); souvenir != "" {
+		isSouvenir, err = strconv.ParseBool(souvenir)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid souvenir %q, expected true or false", souvenir))
+			return
+		}
+	}
+
+	count, err := intParam(query.Get("count"), defaultListingCount)
+	if err != nil || count < 1 || count > maxListingCount {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid count %q, expected 1-%d", query.Get("count"), maxListingCount))
+		return
+	}
+
+	limit, err := intParam(query.Get("limit"), 0)
+	if err != nil || limit < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", query.Get("limit")))
+		return
+	}
+
+	var sortBy *steam.Sort
+	if option := query.Get("sort"); option != "" {
+		parsed, err := steam.ParseSort(option)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		sortBy = &parsed
+	}
+
+	variants := steam.NewVariants(wearTiers, isSouvenir, statTrakOptions...)
+	key := fmt.Sprintf("listings\x00%s\x00%s\x00%v\x00%d", strings.ToLower(name), assetType, variants, count)
+
+	value, err := server.lookup(key, func() (interface{}, error) {
+		assetList, _, err := server.client.NewAssetVariants(name, assetType, variants, count)
+		if err != nil {
+			return nil, err
+		}
+		result := scan{assetList: []steam.SimpleAsset{}}
+		if assetList != nil {
+			result.assetList = *assetList
+		}
+		metrics.ListingsScanned(len(result.assetList))
+		result.matches = server.match(result.assetList)
+		return result, nil
+	})
+	if err != nil {
+		writeLookupError(w, r, err)
+		return
+	}
+
+	// The cached scan is shared, so sort a copy.
+	result := value.(scan)
+	assetList := append([]steam.SimpleAsset{}, result.assetList...)
+	if sortBy != nil {
+		steam.SortAssets(assetList, *sortBy)
+	}
+	if limit > 0 && len(assetList) > limit {
+		assetList = assetList[:limit]
+	}
+
+	writeJSON(w, http.StatusOK, output.Listings(assetList, result.matches))
+}
+
+// match finds the highlights of a fresh scan and alerts about them.
+func (server *Server) match(assetList []steam.SimpleAsset) []steam.RuleMatch {
+	if server.config.Match == nil {
+		return nil
+	}
+
+	matches := server.config.Match(assetList)
+	for _, match := range matches {
+		alert := notify.NewAlert(match.Rule, match.Asset)
+		metrics.AlertFired(match.Rule)
+		server.Notify(alert)
+		if server.config.Notifier == nil {
+			continue
+		}
+		err := server.config.Notifier.Notify(alert)
+		if err != nil {
+			server.logger().Warn("failed to send alert", "listing_id", match.Asset.ListingID, "rule", match.Rule, "error", err)
+		}
+	}
+	return matches
+}
+
+// handleInspect looks up a single item, e.g. /inspect?url=steam://rungame/...
+func (server *Server) handleInspect(w http.ResponseWriter, r *http.Request) {
+	inspectURL := r.URL.Query().Get("url")
+	if inspectURL == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing url"))
+		return
+	}
+
+	value, err := server.lookup("inspect\x00"+inspectURL, func() (interface{}, error) {
+		return server.client.Inspect(inspectURL)
+	})
+	if err != nil {
+		writeLookupError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, value)
+}
+
+// handlePrice looks up the price overview of an item by its market hash
+// name, e.g. /price?name=AK-47 | Redline (Field-Tested).
+func (server *Server) handlePrice(w http.ResponseWriter, r *http.Request) {
+	marketHashName := strings.TrimSpace(r.URL.Query().Get("name"))
+	if marketHashName == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing name"))
+		return
+	}
+
+	value, err := server.lookup("price\x00"+marketHashName, func() (interface{}, error) {
+		return server.client.GetPriceOverview(marketHashName)
+	})
+	if err != nil {
+		writeLookupError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, value)
+}
+
+// handleAlerts lists the alerts found so far, newest first, e.g.
+// /alerts?since=2024-01-02T15:04:05Z&limit=10.
+func (server *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	since := time.Time{}
+	if option := query.Get("since"); option != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, option)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid since %q, expected an RFC 3339 time", option))
+			return
+		}
+	}
+
+	limit, err := intParam(query.Get("limit"), 0)
+	if err != nil || limit < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", query.Get("limit")))
+		return
+	}
+
+	server.mu.Lock()
+	alerts := []Alert{}
+	for i := len(server.alerts) - 1; i >= 0; i-- {
+		if !server.alerts[i].Time.After(since) || (limit > 0 && len(alerts) == limit) {
+			break
+		}
+		alerts = append(alerts, server.alerts[i])
+	}
+	server.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, alerts)
+}
+
+// onlyGet rejects requests that are not GETs.
+func onlyGet(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// writeJSON writes a value as the JSON response.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		slog.Warn("failed to write response", "error", err)
+	}
+}
+
+// writeError writes an error as the JSON response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeLookupError writes the error of a lookup, telling the caller when to
+// retry if it was rate limited.
+func writeLookupError(w http.ResponseWriter, r *http.Request, err error) {
+	requestLogger(r).Warn("lookup failed", "path", r.URL.Path, "query", r.URL.RawQuery, "error", err)
+	if rateLimited, ok := err.(rateLimitError); ok {
+		seconds := int(math.Ceil(rateLimited.retryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		writeError(w, http.StatusTooManyRequests, err)
+		return
+	}
+	writeError(w, http.StatusBadGateway, err)
+}
+
+// valueOr is the value, or the fallback if it is empty.
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// intParam parses an integer query parameter, or the fallback if it is empty.
+func intParam(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
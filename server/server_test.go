@@ -0,0 +1,292 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"eiffel65/output"
+	"eiffel65/steam"
+)
+
+const testInspectLink = "steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20M%listingid%A%assetid%D7001"
+
+// fakeSteam starts a fake market and float API with two listings of the
+// Case Hardened, counting the requests it is sent.
+func fakeSteam(t *testing.T, requests *int32) *steam.Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/market/listings/730/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		fmt.Fprintf(w, `{
+			"success": true,
+			"total_count": 2,
+			"listinginfo": {
+				"101": {"listingid": "101", "converted_price": 1000, "converted_fee": 150, "asset": {"id": "11"}},
+				"102": {"listingid": "102", "converted_price": 2000, "converted_fee": 300, "asset": {"id": "12"}}
+			},
+			"assets": {"730": {"2": {
+				"11": {"id": "11", "market_actions": [{"name": "Inspect in Game...", "link": %[1]q}]},
+				"12": {"id": "12", "market_actions": [{"name": "Inspect in Game...", "link": %[1]q}]}
+			}}}
+		}`, testInspectLink)
+	})
+	mux.HandleFunc("/market/priceoverview", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		fmt.Fprint(w, `{"success": true, "lowest_price": "£10.00", "median_price": "£12.50", "volume": "42"}`)
+	})
+	mux.HandleFunc("/float/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		seed := 661
+		if strings.Contains(r.URL.RawQuery, "A12D") {
+			seed = 12
+		}
+		fmt.Fprintf(w, `{"iteminfo": {"defindex": 7, "paintindex": 44, "paintseed": %d, "floatvalue": 0.%d}}`, seed, seed)
+	})
+
+	fake := httptest.NewServer(mux)
+	t.Cleanup(fake.Close)
+
+	client := steam.NewClient("")
+	client.MarketBaseURL = fake.URL
+	client.FloatBaseURL = fake.URL + "/float/"
+	return client
+}
+
+// get requests a path from the server, decoding the JSON response.
+func get(t *testing.T, server *Server, path string, value interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	if value != nil && recorder.Code == http.StatusOK {
+		err := json.NewDecoder(recorder.Body).Decode(value)
+		if err != nil {
+			t.Fatalf("failed to decode %s: %s", path, err)
+		}
+	}
+	return recorder
+}
+
+func TestListings(t *testing.T) {
+	requests := int32(0)
+	server := New(fakeSteam(t, &requests), Config{
+		CacheTTL: time.Minute,
+		Match: func(assetList []steam.SimpleAsset) []steam.RuleMatch {
+			matches := []steam.RuleMatch{}
+			for _, asset := range steam.CheckForRarity(assetList) {
+				matches = append(matches, steam.RuleMatch{Rule: "rare pattern", Asset: asset})
+			}
+			return matches
+		},
+	})
+
+	path := "/listings?" + url.Values{"name": {"AK-47 | Case Hardened"}, "sort": {"seed"}}.Encode()
+	listings := []output.Listing{}
+	recorder := get(t, server, path, &listings)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", recorder.Code, recorder.Body)
+	}
+	if len(listings) != 2 || listings[0].ListingID != "102" || listings[1].ListingID != "101" {
+		t.Fatalf("got %+v", listings)
+	}
+	if listings[0].Highlighted() || !listings[1].Highlighted() {
+		t.Errorf("expected only seed 661 to be highlighted, got %+v", listings)
+	}
+	if listings[1].ListingTotalPrice != "11.50" {
+		t.Errorf("got total price %q, want 11.50", listings[1].ListingTotalPrice)
+	}
+
+	// A repeat is served from the cache, with its own sort and limit.
+	before := atomic.LoadInt32(&requests)
+	path = "/listings?" + url.Values{"name": {"AK-47 | Case Hardened"}, "limit": {"1"}}.Encode()
+	listings = []output.Listing{}
+	get(t, server, path, &listings)
+	if atomic.LoadInt32(&requests) != before {
+		t.Errorf("expected the repeat to be cached, got %d more requests", atomic.LoadInt32(&requests)-before)
+	}
+	if len(listings) != 1 || listings[0].ListingID != "101" {
+		t.Errorf("got %+v", listings)
+	}
+
+	alerts := []Alert{}
+	get(t, server, "/alerts", &alerts)
+	if len(alerts) != 1 || alerts[0].ListingID != "101" || alerts[0].Rule != "rare pattern" {
+		t.Errorf("expected one alert for the fresh scan, got %+v", alerts)
+	}
+}
+
+func TestBadRequests(t *testing.T) {
+	server := New(steam.NewClient(""), Config{})
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/listings", http.StatusBadRequest},
+		{"/listings?name=AK-47&wear=9", http.StatusBadRequest},
+		{"/listings?name=AK-47&stattrak=maybe", http.StatusBadRequest},
+		{"/listings?name=AK-47&count=500", http.StatusBadRequest},
+		{"/listings?name=AK-47&sort=colour", http.StatusBadRequest},
+		{"/inspect", http.StatusBadRequest},
+		{"/price", http.StatusBadRequest},
+		{"/alerts?since=yesterday", http.StatusBadRequest},
+		{"/nothing", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		recorder := get(t, server, test.path, nil)
+		if recorder.Code != test.status {
+			t.Errorf("%s: got %d, want %d", test.path, recorder.Code, test.status)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/price?name=x", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: got %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	requests := int32(0)
+	server := New(fakeSteam(t, &requests), Config{CacheTTL: time.Minute, RateLimit: 2})
+
+	for i, name := range []string{"a", "b", "a", "c"} {
+		recorder := get(t, server, "/price?name="+name, nil)
+		want := http.StatusOK
+		if name == "c" {
+			want = http.StatusTooManyRequests
+		}
+		if recorder.Code != want {
+			t.Errorf("request %d for %s: got %d, want %d", i, name, recorder.Code, want)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests to Steam, want 2", requests)
+	}
+
+	recorder := get(t, server, "/price?name=c", nil)
+	if recorder.Header().Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header")
+	}
+}
+
+func TestInspect(t *testing.T) {
+	requests := int32(0)
+	server := New(fakeSteam(t, &requests), Config{})
+
+	asset := steam.SimpleAsset{}
+	recorder := get(t, server, "/inspect?"+url.Values{"url": {"steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20M1A12D7001"}}.Encode(), &asset)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", recorder.Code, recorder.Body)
+	}
+	if asset.Float.PaintSeed != 12 || asset.Name != "AK-47 | Case Hardened" {
+		t.Errorf("got %+v", asset)
+	}
+}
+
+func TestCacheSharesLookups(t *testing.T) {
+	c := newCache(time.Minute)
+	calls := int32(0)
+	release := make(chan struct{})
+
+	done := make(chan interface{})
+	for i := 0; i < 3; i++ {
+		go func() {
+			value, _ := c.get("key", func() (interface{}, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return "value", nil
+			})
+			done <- value
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	for i := 0; i < 3; i++ {
+		if value := <-done; value != "value" {
+			t.Errorf("got %v", value)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("got %d lookups, want 1", calls)
+	}
+
+	_, err := c.get("failing", func() (interface{}, error) { return nil, fmt.Errorf("failed") })
+	if err == nil {
+		t.Fatal("expected the error")
+	}
+	value, err := c.get("failing", func() (interface{}, error) { return "retried", nil })
+	if err != nil || value != "retried" {
+		t.Errorf("expected errors not to be cached, got %v, %v", value, err)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(60)
+	now := time.Now()
+	for i := 0; i < 60; i++ {
+		if ok, _ := l.allow(now); !ok {
+			t.Fatalf("lookup %d of the burst was limited", i)
+		}
+	}
+	ok, retryAfter := l.allow(now)
+	if ok || retryAfter != time.Second {
+		t.Errorf("got %t, %s, want limited for 1s", ok, retryAfter)
+	}
+	if ok, _ := l.allow(now.Add(time.Second)); !ok {
+		t.Error("expected a token after a second")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	logs := bytes.Buffer{}
+	server := New(steam.NewClient(""), Config{Logger: slog.New(slog.NewJSONHandler(&logs, nil))})
+
+	recorder := get(t, server, "/listings", nil)
+	generated := recorder.Header().Get(requestIDHeader)
+	if generated == "" {
+		t.Fatal("expected a request ID to be generated")
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/price", nil)
+	request.Header.Set(requestIDHeader, "abc123")
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+	if got := recorder.Header().Get(requestIDHeader); got != "abc123" {
+		t.Errorf("got request ID %q, want the caller's", got)
+	}
+
+	type logEntry struct {
+		RequestID string `json:"request_id"`
+		Path      string `json:"path"`
+		Status    int    `json:"status"`
+	}
+	want := []logEntry{
+		{RequestID: generated, Path: "/listings", Status: http.StatusBadRequest},
+		{RequestID: "abc123", Path: "/price", Status: http.StatusBadRequest},
+	}
+
+	got := []logEntry{}
+	decoder := json.NewDecoder(&logs)
+	for decoder.More() {
+		entry := logEntry{}
+		err := decoder.Decode(&entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, entry)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got logs %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package steam
+
+import "strings"
+
+// Steam's currency codes, enums: https://github.com/SteamRE/SteamKit/blob/master/Resources/SteamLanguage/enums.steamd
+var currencyCodes = map[string]string{
+	"USD": "1",
+	"GBP": "2",
+	"EUR": "3",
+	"CHF": "4",
+	"RUB": "5",
+	"PLN": "6",
+	"BRL": "7",
+	"JPY": "8",
+	"CAD": "20",
+	"AUD": "21",
+	"NZD": "22",
+	"CNY": "23",
+}
+
+// CurrencyCode is Steam's number for a currency given by its code, such as
+// GBP, or by the number itself, reporting whether it is known.
+func CurrencyCode(currency string) (string, bool) {
+	if code, ok := currencyCodes[strings.ToUpper(currency)]; ok {
+		return code, true
+	}
+	for _, code := range currencyCodes {
+		if code == currency {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// CurrencyName is the code, such as GBP, of one of Steam's currency numbers,
+// or the number itself if it is not known.
+func CurrencyName(code string) string {
+	for name, currencyCode := range currencyCodes {
+		if currencyCode == code {
+			return name
+		}
+	}
+	return code
+}
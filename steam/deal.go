@@ -0,0 +1,136 @@
+package steam
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// comparableFloatRange is how close another listing's float must be for
+	// its price to be comparable.
+	comparableFloatRange float64 = 0.02
+	// minComparables is the fewest comparable listings needed to judge a
+	// price against them.
+	minComparables int = 2
+	// DealDiscount is how far below comparable floats, as a percentage, a
+	// listing must be priced to be highlighted as a deal.
+	DealDiscount float64 = 30
+	// tierBonus is the score added for a tier 1 pattern, shared out for
+	// commoner tiers.
+	tierBonus float64 = 50
+	// floatBonus is the most the float percentile adds to or takes off the
+	// score.
+	floatBonus float64 = 10
+)
+
+// DealScore rates how good a deal a listing is against the market median
+// and against listings of the same item with a similar float.
+type DealScore struct {
+	// Score combines the discount, float percentile and pattern tier, higher
+	// being a better deal.
+	Score float64 `json:"score"`
+	// MedianDiscount is how far below the price overview median, as a
+	// percentage, the listing is priced. Negative is above it.
+	MedianDiscount float64 `json:"median_discount,omitempty"`
+	// ComparableDiscount is how far below the median of comparable listings,
+	// as a percentage, the listing is priced.
+	ComparableDiscount float64 `json:"comparable_discount,omitempty"`
+	Comparables        int     `json:"comparables,omitempty"`
+	// FloatPercentile is the percentage of listings of the same item with a
+	// higher, so worse, float.
+	FloatPercentile float64 `json:"float_percentile"`
+}
+
+// ScoreDeals scores each listing against the others in the pool with the
+// same market name. The pool is usually the whole scan, so listings reported
+// on their own can still be compared to everything seen alongside them.
+// Median discounts are only included for listings priced by PriceAssets.
+func ScoreDeals(assetList []SimpleAsset, pool []SimpleAsset) {
+	byName := map[string][]SimpleAsset{}
+	for _, asset := range pool {
+		byName[asset.Name] = append(byName[asset.Name], asset)
+	}
+
+	for i := range assetList {
+		asset := &assetList[i]
+		deal := DealScore{}
+		price := totalPrice(*asset)
+
+		if median, err := asset.MarketValue.Price(); err == nil && median > 0 && price > 0 {
+			deal.MedianDiscount = discount(price, median)
+		}
+
+		worse, others, comparablePrices := 0, 0, []float64{}
+		for _, other := range byName[asset.Name] {
+			if ListingKey(other) == ListingKey(*asset) || other.Float.FloatValue == 0 {
+				continue
+			}
+			others++
+			if other.Float.FloatValue > asset.Float.FloatValue {
+				worse++
+			}
+			if math.Abs(other.Float.FloatValue-asset.Float.FloatValue) <= comparableFloatRange && totalPrice(other) > 0 {
+				comparablePrices = append(comparablePrices, totalPrice(other))
+			}
+		}
+
+		if others > 0 && asset.Float.FloatValue != 0 {
+			deal.FloatPercentile = float64(worse) / float64(others) * 100
+		}
+		if len(comparablePrices) >= minComparables && price > 0 {
+			deal.Comparables = len(comparablePrices)
+			deal.ComparableDiscount = discount(price, median(comparablePrices))
+		}
+
+		deal.Score = dealScore(deal, asset.RarityTier, others > 0)
+		asset.Deal = &deal
+	}
+}
+
+// dealScore combines the parts of a deal, preferring the comparable
+// discount as it accounts for float, then the median discount. The float
+// percentile only counts when there were other listings to rank against. A
+// tier 1 pattern adds the full tier bonus, tier 2 half of it and so on.
+func dealScore(deal DealScore, tier int, ranked bool) float64 {
+	score := deal.MedianDiscount
+	if deal.Comparables > 0 {
+		score = deal.ComparableDiscount
+	}
+	score = math.Max(-100, math.Min(100, score))
+
+	if ranked {
+		score += (deal.FloatPercentile - 50) / 50 * floatBonus
+	}
+	if tier > 0 {
+		score += tierBonus / float64(tier)
+	}
+	return math.Round(score*100) / 100
+}
+
+// CheckForDeals finds the listings priced at least DealDiscount below
+// comparable floats, once ScoreDeals has run.
+func CheckForDeals(assetList []SimpleAsset) []SimpleAsset {
+	deals := []SimpleAsset{}
+	for _, asset := range assetList {
+		if asset.Deal != nil && asset.Deal.Comparables > 0 && asset.Deal.ComparableDiscount >= DealDiscount {
+			deals = append(deals, asset)
+		}
+	}
+	return deals
+}
+
+// discount is how far below the reference a price is, as a percentage.
+func discount(price, reference float64) float64 {
+	return math.Round((reference-price)/reference*10000) / 100
+}
+
+// median is the middle of a list of prices.
+func median(prices []float64) float64 {
+	sorted := append([]float64{}, prices...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
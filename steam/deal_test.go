@@ -0,0 +1,92 @@
+package steam
+
+import (
+	"testing"
+
+	"eiffel65/float"
+)
+
+// dealTestAssets are listings of one item, three of them with similar
+// floats and one priced well below the others.
+var dealTestAssets = []SimpleAsset{
+	{ListingID: "1", Name: "AK-47 | Redline (Field-Tested)", ListingTotalPrice: "6.00", Float: float.AssetFloat{FloatValue: 0.200}},
+	{ListingID: "2", Name: "AK-47 | Redline (Field-Tested)", ListingTotalPrice: "10.00", Float: float.AssetFloat{FloatValue: 0.205}},
+	{ListingID: "3", Name: "AK-47 | Redline (Field-Tested)", ListingTotalPrice: "12.00", Float: float.AssetFloat{FloatValue: 0.210}},
+	{ListingID: "4", Name: "AK-47 | Redline (Field-Tested)", ListingTotalPrice: "9.00", Float: float.AssetFloat{FloatValue: 0.350}},
+	{ListingID: "5", Name: "AK-47 | Case Hardened (Field-Tested)", ListingTotalPrice: "80.00", RarityTier: 1, Float: float.AssetFloat{FloatValue: 0.200}},
+}
+
+func TestScoreDeals(t *testing.T) {
+	assetList := append([]SimpleAsset{}, dealTestAssets...)
+	assetList[0].MarketValue = AssetValue{MedianPrice: "£8.00"}
+	ScoreDeals(assetList, assetList)
+
+	cheap := assetList[0].Deal
+	if cheap == nil {
+		t.Fatal("expected a deal score")
+	}
+	// Compared to 10.00 and 12.00, the median of which is 11.00.
+	if cheap.Comparables != 2 || cheap.ComparableDiscount != 45.45 {
+		t.Errorf("got comparables %d at %v%%, want 2 at 45.45%%", cheap.Comparables, cheap.ComparableDiscount)
+	}
+	if cheap.MedianDiscount != 25 {
+		t.Errorf("got median discount %v, want 25", cheap.MedianDiscount)
+	}
+	if cheap.FloatPercentile != 100 {
+		t.Errorf("got float percentile %v, want 100 as every other float is higher", cheap.FloatPercentile)
+	}
+	if cheap.Score != 55.45 {
+		t.Errorf("got score %v, want 55.45", cheap.Score)
+	}
+
+	if assetList[3].Deal.Comparables != 0 || assetList[3].Deal.FloatPercentile != 0 {
+		t.Errorf("got %+v, want no comparables for the outlying float", assetList[3].Deal)
+	}
+
+	// A tier 1 pattern with nothing to compare to still scores well.
+	if assetList[4].Deal.Score != 50 {
+		t.Errorf("got tier 1 score %v, want 50", assetList[4].Deal.Score)
+	}
+
+	deals := CheckForDeals(assetList)
+	if len(deals) != 1 || deals[0].ListingID != "1" {
+		t.Errorf("got deals %v, want only listing 1", deals)
+	}
+}
+
+func TestScoreDealsAgainstPool(t *testing.T) {
+	newListings := []SimpleAsset{dealTestAssets[0]}
+	ScoreDeals(newListings, dealTestAssets)
+
+	if newListings[0].Deal == nil || newListings[0].Deal.Comparables != 2 {
+		t.Errorf("got %+v, want the new listing compared against the pool", newListings[0].Deal)
+	}
+}
+
+func TestDealRulesAndSort(t *testing.T) {
+	assetList := append([]SimpleAsset{}, dealTestAssets...)
+	ScoreDeals(assetList, assetList)
+
+	matches := EvaluateRules([]Rule{{Name: "deals", MinComparableDiscount: 30}, {Name: "score", MinDealScore: 50}}, assetList)
+	got := []string{}
+	for _, match := range matches {
+		got = append(got, match.Asset.ListingID+":"+match.Rule)
+	}
+	if len(got) != 3 || got[0] != "1:deals" || got[1] != "1:score" || got[2] != "5:score" {
+		t.Errorf("got %v", got)
+	}
+
+	SortAssets(assetList, Sort{Key: SortDeal})
+	if sortedIDs(assetList) != "1,5,2,4,3" {
+		t.Errorf("got %s sorted by deal", sortedIDs(assetList))
+	}
+}
+
+func TestMedian(t *testing.T) {
+	if got := median([]float64{3, 1, 2}); got != 2 {
+		t.Errorf("got %v, want 2", got)
+	}
+	if got := median([]float64{4, 1, 3, 2}); got != 2.5 {
+		t.Errorf("got %v, want 2.5", got)
+	}
+}
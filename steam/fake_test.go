@@ -0,0 +1,290 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeInspectLink is the inspect link the fake market gives every listing,
+// with the placeholders the real market leaves in.
+const fakeInspectLink = "steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20M%listingid%A%assetid%D7001"
+
+// fakeInventoryLink is the inspect link the fake inventory gives every skin,
+// with the placeholders the real community leaves in.
+const fakeInventoryLink = "steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20S%owner_steamid%A%assetid%D7002"
+
+// fakeInventoryPageSize is how many items the fake inventory serves a page,
+// so a few are enough to page.
+const fakeInventoryPageSize = 2
+
+// fakeSteam mimics the market listings render, market search, inventory,
+// price overview, GetAssetClassInfo and float APIs, keeping every request it
+// is sent.
+type fakeSteam struct {
+	// Listings is how many listings the item has, numbered from 1. Listing n
+	// sells asset 1000+n, which has paint seed n and costs n pounds with the
+	// fees.
+	Listings int
+	// Inventory is how many skins the inventory holds besides a case. Skin n
+	// is asset 1000+n, which has paint seed n.
+	Inventory int
+	// PrivateInventory makes the inventory answer as a private one does.
+	PrivateInventory bool
+	// SearchResults is how many items the market search finds, numbered
+	// from 1. Result n is the Sticker | Fake n.
+	SearchResults int
+	// SearchFails makes the market search answer that it was unsuccessful.
+	SearchFails bool
+	// Status, if set, is returned by every endpoint instead of a response.
+	Status int
+	// FloatFails lists the asset IDs the float API fails to look up.
+	FloatFails map[string]bool
+	// ListingsFail lists the market names whose listings fail to load.
+	ListingsFail map[string]bool
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// client starts the fake and returns a client pointed at it.
+func (fake *fakeSteam) client(t *testing.T) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/market/listings/730/", fake.handleListings)
+	mux.HandleFunc("/market/search/render", fake.handleSearch)
+	mux.HandleFunc("/inventory/", fake.handleInventory)
+	mux.HandleFunc("/market/priceoverview", fake.handlePriceOverview)
+	mux.HandleFunc("/ISteamEconomy/GetAssetClassInfo/v0001", fake.handleAssetClassInfo)
+	mux.HandleFunc("/float/", fake.handleFloat)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		fake.requests = append(fake.requests, r.URL.Path+"?"+r.URL.RawQuery)
+		fake.mu.Unlock()
+
+		if fake.Status != 0 {
+			w.WriteHeader(fake.Status)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("test-key")
+	client.MarketBaseURL = server.URL
+	client.APIBaseURL = server.URL
+	client.FloatBaseURL = server.URL + "/float/"
+	return client
+}
+
+// Requests returns the requests sent to the fake so far.
+func (fake *fakeSteam) Requests() []string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]string{}, fake.requests...)
+}
+
+// handleListings serves a page of listings from start, count of them or 10
+// without a count, as the market does. Every item has the same listings.
+func (fake *fakeSteam) handleListings(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("format") != "json" {
+		http.Error(w, "expected format=json", http.StatusBadRequest)
+		return
+	}
+	marketName := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/market/listings/730/"), "/render")
+	if fake.ListingsFail[marketName] {
+		http.Error(w, "listings unavailable", http.StatusInternalServerError)
+		return
+	}
+	start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil {
+		count = 10
+	}
+
+	listingInfo := map[string]interface{}{}
+	assets := map[string]interface{}{}
+	for n := start + 1; n <= start+count && n <= fake.Listings; n++ {
+		listingID := strconv.Itoa(n)
+		assetID := strconv.Itoa(1000 + n)
+		listingInfo[listingID] = map[string]interface{}{
+			"listingid":       listingID,
+			"converted_price": n * 87,
+			"converted_fee":   n * 13,
+			"asset":           map[string]interface{}{"id": assetID, "appid": 730, "contextid": "2", "amount": "1"},
+		}
+		assets[assetID] = map[string]interface{}{
+			"id":             assetID,
+			"classid":        "310776560",
+			"instanceid":     "302028390",
+			"contextid":      "2",
+			"type":           "Classified Rifle",
+			"market_actions": []map[string]string{{"name": "Inspect in Game...", "link": fakeInspectLink}},
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"start":       start,
+		"pagesize":    count,
+		"total_count": fake.Listings,
+		"listinginfo": listingInfo,
+		"assets":      map[string]interface{}{"730": map[string]interface{}{"2": assets}},
+	})
+}
+
+// handleSearch serves a page of search results from start, count of them or
+// 10 without a count, as the market does.
+func (fake *fakeSteam) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if fake.SearchFails {
+		fmt.Fprint(w, `{"success": false}`)
+		return
+	}
+	start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil {
+		count = 10
+	}
+
+	results := []map[string]interface{}{}
+	for n := start + 1; n <= start+count && n <= fake.SearchResults; n++ {
+		name := fmt.Sprintf("Sticker | Fake %d", n)
+		results = append(results, map[string]interface{}{
+			"name":          name,
+			"hash_name":     name,
+			"sell_listings": n,
+			"sell_price":    n * 100,
+			"asset_description": map[string]interface{}{
+				"classid":          strconv.Itoa(2000 + n),
+				"market_hash_name": name,
+			},
+		})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"start":       start,
+		"pagesize":    count,
+		"total_count": fake.SearchResults,
+		"results":     results,
+	})
+}
+
+// handleInventory serves a page of the inventory from start_assetid, the
+// skins followed by a case, which shares its description with none of them.
+func (fake *fakeSteam) handleInventory(w http.ResponseWriter, r *http.Request) {
+	if fake.PrivateInventory {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "null")
+		return
+	}
+
+	// The case is asset 1000, which sorts it last.
+	assetIDs := []int{}
+	for n := 1; n <= fake.Inventory; n++ {
+		assetIDs = append(assetIDs, 1000+n)
+	}
+	assetIDs = append(assetIDs, 1000)
+
+	start := 0
+	if startAssetID := r.URL.Query().Get("start_assetid"); startAssetID != "" {
+		for i, assetID := range assetIDs {
+			if strconv.Itoa(assetID) == startAssetID {
+				start = i + 1
+			}
+		}
+	}
+	end := start + fakeInventoryPageSize
+	if end > len(assetIDs) {
+		end = len(assetIDs)
+	}
+
+	assets := []map[string]interface{}{}
+	for _, assetID := range assetIDs[start:end] {
+		classID := "310776560"
+		if assetID == 1000 {
+			classID = "4548409034"
+		}
+		assets = append(assets, map[string]interface{}{
+			"appid": 730, "contextid": "2", "assetid": strconv.Itoa(assetID), "classid": classID, "instanceid": "0", "amount": "1",
+		})
+	}
+
+	page := map[string]interface{}{
+		"success":               1,
+		"total_inventory_count": len(assetIDs),
+		"assets":                assets,
+		"descriptions": []map[string]interface{}{
+			{
+				"classid":          "310776560",
+				"instanceid":       "0",
+				"icon_url":         "ak47",
+				"market_hash_name": "AK-47 | Case Hardened (Field-Tested)",
+				"type":             "Classified Rifle",
+				"marketable":       1,
+				"actions":          []map[string]string{{"name": "Inspect in Game...", "link": fakeInventoryLink}},
+			},
+			{
+				"classid":          "4548409034",
+				"instanceid":       "0",
+				"market_hash_name": "Revolution Case",
+				"type":             "Base Grade Container",
+				"marketable":       1,
+			},
+		},
+	}
+	if end < len(assetIDs) {
+		page["more_items"] = 1
+		page["last_assetid"] = strconv.Itoa(assetIDs[end-1])
+	}
+	json.NewEncoder(w).Encode(page)
+}
+
+// handlePriceOverview prices every item at £10 to £12.50, except those
+// named "Unknown", which the market does not find.
+func (fake *fakeSteam) handlePriceOverview(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Query().Get("market_hash_name"), "Unknown") {
+		fmt.Fprint(w, `{"success": false}`)
+		return
+	}
+	fmt.Fprint(w, `{"success": true, "lowest_price": "£10.00", "median_price": "£12.50", "volume": "42"}`)
+}
+
+// handleAssetClassInfo describes class 310776560 only.
+func (fake *fakeSteam) handleAssetClassInfo(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("key") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	classID := r.URL.Query().Get("classid0")
+	if classID != "310776560" {
+		fmt.Fprint(w, `{"result": {"success": true}}`)
+		return
+	}
+	fmt.Fprint(w, `{"result": {"310776560": {"classid": "310776560", "name": "AK-47 | Case Hardened", "market_name": "AK-47 | Case Hardened (Field-Tested)", "market_hash_name": "AK-47 | Case Hardened (Field-Tested)", "type": "Classified Rifle", "tradable": 1, "marketable": 1}, "success": true}}`)
+}
+
+// handleFloat reads the asset ID out of the inspect link and gives the item
+// the paint seed of its listing.
+func (fake *fakeSteam) handleFloat(w http.ResponseWriter, r *http.Request) {
+	link := r.URL.RawQuery
+	a, d := strings.LastIndex(link, "A"), strings.LastIndex(link, "D")
+	if a < 0 || d < a {
+		http.Error(w, "bad inspect link", http.StatusBadRequest)
+		return
+	}
+	assetID := link[a+1 : d]
+	if fake.FloatFails[assetID] {
+		http.Error(w, "inspect timed out", http.StatusGatewayTimeout)
+		return
+	}
+	seed, _ := strconv.Atoi(assetID)
+	seed -= 1000
+	fmt.Fprintf(w, `{"iteminfo": {"defindex": 7, "paintindex": 44, "paintseed": %d, "floatvalue": 0.%03d}}`, seed, seed)
+}
@@ -0,0 +1,95 @@
+package steam
+
+import (
+	"fmt"
+
+	"eiffel65/schema"
+)
+
+// FamilyMember is an item carrying a particular paint.
+type FamilyMember struct {
+	Name string
+	Type AssetType
+}
+
+// FamilyMembers expands a paint name such as "Case Hardened" into every gun,
+// knife and pair of gloves that carries it, using the bundled item schema.
+func FamilyMembers(paintName string) ([]FamilyMember, error) {
+	itemSchema, err := schema.Default()
+	if err != nil {
+		return nil, err
+	}
+	return familyMembers(itemSchema, paintName)
+}
+
+// familyMembers expands a paint name into the items carrying it in a schema.
+func familyMembers(itemSchema *schema.Schema, paintName string) ([]FamilyMember, error) {
+	members := []FamilyMember{}
+	seen := map[string]bool{}
+	for _, skin := range itemSchema.SkinsWithPaint(paintName) {
+		weapon, _ := itemSchema.Weapon(skin.DefIndex)
+		name := itemSchema.MarketName(skin.DefIndex, skin.PaintIndex)
+
+		// Paints such as Doppler have a paint kit per phase but share a name.
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		members = append(members, FamilyMember{Name: name, Type: schemaAssetType(weapon.Kind)})
+	}
+
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no items carry the paint %q in the item schema", paintName)
+	}
+
+	return members, nil
+}
+
+// ScanFamily looks up the listings of every variant of every item carrying a
+// paint and merges them into a single list. Items that fail or have no
+// listings are logged and skipped, and the market names that failed are
+// returned as for NewAssetVariants.
+func (client *Client) ScanFamily(paintName string, variants []AssetVariant, listings int) (*[]SimpleAsset, []string, error) {
+	members, err := FamilyMembers(paintName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	simpleAssetList := []SimpleAsset{}
+	failed := []string{}
+	for _, member := range members {
+		// Knives cannot be Souvenir and gloves cannot be either StatTrak or
+		// Souvenir, so drop those variants rather than fail.
+		memberVariants := []AssetVariant{}
+		for _, variant := range variants {
+			if variantAllowed(member.Type, variant) {
+				memberVariants = append(memberVariants, variant)
+			}
+		}
+		if len(memberVariants) == 0 {
+			continue
+		}
+
+		assets, memberFailed, err := client.NewAssetVariants(member.Name, member.Type, memberVariants, listings)
+		failed = append(failed, memberFailed...)
+		if err != nil {
+			client.logger().Warn("failed to get asset listings", "name", member.Name, "error", err)
+			continue
+		}
+		simpleAssetList = append(simpleAssetList, *assets...)
+	}
+
+	return &simpleAssetList, failed, nil
+}
+
+// variantAllowed reports whether a kind of item comes in a variant.
+func variantAllowed(assetType AssetType, variant AssetVariant) bool {
+	switch assetType {
+	case knifeAsset:
+		return !variant.Souvenir
+	case glovesAsset:
+		return !variant.Souvenir && !variant.StatTrak
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package steam
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"eiffel65/float"
+	"eiffel65/schema"
+)
+
+// testSchema generates the item schema from the excerpts of the game files
+// in the schema package's testdata, so the tests do not depend on what the
+// bundled schema happens to hold.
+func testSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+
+	itemsGame, err := os.Open("../schema/testdata/items_game.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer itemsGame.Close()
+
+	language, err := os.Open("../schema/testdata/csgo_english.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer language.Close()
+
+	itemSchema, err := schema.Generate(itemsGame, language)
+	if err != nil {
+		t.Fatalf("failed to generate schema: %s", err)
+	}
+	return itemSchema
+}
+
+func TestFamilyMembers(t *testing.T) {
+	itemSchema := testSchema(t)
+	members, err := familyMembers(itemSchema, "case hardened")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The Five-SeveN, AK-47 and MAC-10 followed by every knife.
+	if len(members) != 23 {
+		t.Fatalf("got %d members, want 23", len(members))
+	}
+	if members[1] != (FamilyMember{Name: "AK-47 | Case Hardened", Type: weaponAsset}) {
+		t.Errorf("got %+v as the second member", members[1])
+	}
+	if members[3] != (FamilyMember{Name: "Bayonet | Case Hardened", Type: knifeAsset}) {
+		t.Errorf("got %+v as the first knife", members[3])
+	}
+
+	// The excerpt leaves out the AK-47 Redline.
+	if _, err := familyMembers(itemSchema, "Redline"); err == nil {
+		t.Error("expected an error for an unknown family")
+	}
+}
+
+func TestRankHighlights(t *testing.T) {
+	notable := map[string]SimpleAsset{
+		"plain":     {ListingID: "plain", ListingTotalPrice: "10.00"},
+		"tier-1":    {ListingID: "tier-1", RarityTier: 1, ListingTotalPrice: "900.00"},
+		"cheap":     {ListingID: "cheap", RarityTier: 1, ListingTotalPrice: "400.00"},
+		"knife":     {ListingID: "knife", RarityTier: 1, ListingTotalPrice: "1200.00", Float: float.AssetFloat{Rarity: 6}},
+		"tier-2":    {ListingID: "tier-2", RarityTier: 2, ListingTotalPrice: "5.00"},
+		"same-cost": {ListingID: "same-cost", ListingTotalPrice: "10.00"},
+	}
+
+	ids := []string{}
+	for _, asset := range RankHighlights(notable) {
+		ids = append(ids, asset.ListingID)
+	}
+
+	want := []string{"knife", "cheap", "tier-1", "tier-2", "plain", "same-cost"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+}
+
+func TestWearPossible(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		wear AssetWear
+		want bool
+	}{
+		{"Karambit | Fade", factoryNew, true},
+		{"Karambit | Fade", minimalWear, true},
+		{"Karambit | Fade", fieldTested, false},
+		{"Karambit | Slaughter", wellWorn, false},
+		{"AK-47 | Case Hardened", battleScared, true},
+		{"AK-47 | Unknown Paint", battleScared, true},
+		{"Karambit", "", true},
+	} {
+		if got := wearPossible(test.name, test.wear); got != test.want {
+			t.Errorf("%s (%s): got %t, want %t", test.name, test.wear, got, test.want)
+		}
+	}
+}
+
+func TestVariantAllowed(t *testing.T) {
+	if variantAllowed(knifeAsset, AssetVariant{Souvenir: true}) {
+		t.Error("knives cannot be Souvenir")
+	}
+	if variantAllowed(glovesAsset, AssetVariant{StatTrak: true}) {
+		t.Error("gloves cannot be StatTrak")
+	}
+	if !variantAllowed(weaponAsset, AssetVariant{Souvenir: true}) {
+		t.Error("weapons can be Souvenir")
+	}
+}
@@ -0,0 +1,130 @@
+package steam
+
+import "math"
+
+const (
+	// steamFeePercent is Steam's cut of every market sale.
+	steamFeePercent int = 5
+	// csgoFeePercent is the game's cut of every market sale.
+	csgoFeePercent int = 10
+	// minFee is the least either fee can be, in cents.
+	minFee int = 1
+	// feeIterationLimit bounds the search for a seller amount that adds up
+	// to a buyer price.
+	feeIterationLimit int = 10
+)
+
+// Fees splits a market price, in cents, into what the seller receives and
+// the fees the buyer pays on top.
+type Fees struct {
+	BuyerPays      int `json:"buyer_pays"`
+	SellerReceives int `json:"seller_receives"`
+	SteamFee       int `json:"steam_fee"`
+	GameFee        int `json:"game_fee"`
+}
+
+// ProfitEstimate is the net profit from buying a listing and relisting it.
+// Prices are in the listing's currency.
+type ProfitEstimate struct {
+	Cost float64 `json:"cost"`
+	// MedianNet is what the seller receives after fees when relisting at the
+	// market median, and MedianProfit that less the cost.
+	MedianNet    float64 `json:"median_net,omitempty"`
+	MedianProfit float64 `json:"median_profit,omitempty"`
+	// TargetPrice is a price chosen to relist at, with TargetNet and
+	// TargetProfit worked out the same way.
+	TargetPrice  float64 `json:"target_price,omitempty"`
+	TargetNet    float64 `json:"target_net,omitempty"`
+	TargetProfit float64 `json:"target_profit,omitempty"`
+}
+
+// FeesForSellerReceives works out what a buyer pays for the seller to
+// receive an amount, in cents. Each fee is rounded down but is at least a
+// cent.
+func FeesForSellerReceives(received int) Fees {
+	steamFee := received * steamFeePercent / 100
+	if steamFee < minFee {
+		steamFee = minFee
+	}
+	gameFee := received * csgoFeePercent / 100
+	if gameFee < minFee {
+		gameFee = minFee
+	}
+
+	return Fees{
+		BuyerPays:      received + steamFee + gameFee,
+		SellerReceives: received,
+		SteamFee:       steamFee,
+		GameFee:        gameFee,
+	}
+}
+
+// FeesForBuyerPays works out what the seller receives when a buyer pays a
+// price, in cents, the way the market does. Not every price can be made
+// from a whole seller amount, and any cent left over goes to the Steam fee.
+func FeesForBuyerPays(price int) Fees {
+	if price <= 2*minFee {
+		return Fees{BuyerPays: price, SteamFee: price}
+	}
+
+	received := price * 100 / (100 + steamFeePercent + csgoFeePercent)
+	fees := FeesForSellerReceives(received)
+	undershot := false
+	for i := 0; fees.BuyerPays != price && i < feeIterationLimit; i++ {
+		if fees.BuyerPays > price {
+			if undershot {
+				fees = FeesForSellerReceives(received - 1)
+				fees.SteamFee += price - fees.BuyerPays
+				fees.BuyerPays = price
+				break
+			}
+			received--
+		} else {
+			undershot = true
+			received++
+		}
+		fees = FeesForSellerReceives(received)
+	}
+
+	return fees
+}
+
+// EstimateProfit works out the profit of buying each listing and relisting
+// it at the market median, for listings priced by PriceAssets, and at the
+// target price when it is above zero.
+func EstimateProfit(assetList []SimpleAsset, targetPrice float64) {
+	for i := range assetList {
+		asset := &assetList[i]
+		cost := totalPrice(*asset)
+		if cost == 0 {
+			continue
+		}
+
+		estimate := ProfitEstimate{Cost: cost}
+		if median, err := asset.MarketValue.Price(); err == nil && median > 0 {
+			estimate.MedianNet = fromCents(FeesForBuyerPays(toCents(median)).SellerReceives)
+			estimate.MedianProfit = roundCents(estimate.MedianNet - cost)
+		}
+		if targetPrice > 0 {
+			estimate.TargetPrice = targetPrice
+			estimate.TargetNet = fromCents(FeesForBuyerPays(toCents(targetPrice)).SellerReceives)
+			estimate.TargetProfit = roundCents(estimate.TargetNet - cost)
+		}
+		asset.Profit = &estimate
+	}
+}
+
+// toCents converts a price to whole cents.
+func toCents(price float64) int {
+	return int(math.Round(price * 100))
+}
+
+// fromCents converts whole cents to a price.
+func fromCents(cents int) float64 {
+	return float64(cents) / 100
+}
+
+// roundCents rounds a price to the nearest cent.
+func roundCents(price float64) float64 {
+	return math.Round(price*100) / 100
+}
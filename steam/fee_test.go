@@ -0,0 +1,78 @@
+package steam
+
+import "testing"
+
+func TestFeesForSellerReceives(t *testing.T) {
+	tests := []struct {
+		received int
+		want     Fees
+	}{
+		{1, Fees{BuyerPays: 3, SellerReceives: 1, SteamFee: 1, GameFee: 1}},
+		{19, Fees{BuyerPays: 21, SellerReceives: 19, SteamFee: 1, GameFee: 1}},
+		{20, Fees{BuyerPays: 23, SellerReceives: 20, SteamFee: 1, GameFee: 2}},
+		{100, Fees{BuyerPays: 115, SellerReceives: 100, SteamFee: 5, GameFee: 10}},
+		{8700, Fees{BuyerPays: 10005, SellerReceives: 8700, SteamFee: 435, GameFee: 870}},
+	}
+
+	for _, test := range tests {
+		if got := FeesForSellerReceives(test.received); got != test.want {
+			t.Errorf("%d: got %+v, want %+v", test.received, got, test.want)
+		}
+	}
+}
+
+func TestFeesForBuyerPays(t *testing.T) {
+	tests := []struct {
+		price int
+		want  Fees
+	}{
+		{2, Fees{BuyerPays: 2, SteamFee: 2}},
+		{3, Fees{BuyerPays: 3, SellerReceives: 1, SteamFee: 1, GameFee: 1}},
+		{21, Fees{BuyerPays: 21, SellerReceives: 19, SteamFee: 1, GameFee: 1}},
+		// No seller amount adds up to 22, so the spare cent goes to Steam.
+		{22, Fees{BuyerPays: 22, SellerReceives: 19, SteamFee: 2, GameFee: 1}},
+		{115, Fees{BuyerPays: 115, SellerReceives: 100, SteamFee: 5, GameFee: 10}},
+		{116, Fees{BuyerPays: 116, SellerReceives: 101, SteamFee: 5, GameFee: 10}},
+		{10005, Fees{BuyerPays: 10005, SellerReceives: 8700, SteamFee: 435, GameFee: 870}},
+	}
+
+	for _, test := range tests {
+		if got := FeesForBuyerPays(test.price); got != test.want {
+			t.Errorf("%d: got %+v, want %+v", test.price, got, test.want)
+		}
+	}
+}
+
+func TestFeesRoundTrip(t *testing.T) {
+	for received := 1; received < 5000; received++ {
+		fees := FeesForSellerReceives(received)
+		if got := FeesForBuyerPays(fees.BuyerPays); got != fees {
+			t.Fatalf("%d: got %+v back, want %+v", received, got, fees)
+		}
+	}
+}
+
+func TestEstimateProfit(t *testing.T) {
+	assetList := []SimpleAsset{
+		{ListingID: "1", ListingTotalPrice: "80.00", MarketValue: AssetValue{MedianPrice: "£115.00"}},
+		{ListingID: "2", ListingTotalPrice: "80.00"},
+		{ListingID: "3"},
+	}
+	EstimateProfit(assetList, 92)
+
+	profit := assetList[0].Profit
+	if profit == nil || profit.Cost != 80 || profit.MedianNet != 100 || profit.MedianProfit != 20 {
+		t.Errorf("got %+v, want 20.00 profit at the median", profit)
+	}
+	// 92.00 is 80.00 to the seller, exactly the cost.
+	if profit.TargetPrice != 92 || profit.TargetNet != 80 || profit.TargetProfit != 0 {
+		t.Errorf("got %+v, want no profit at the target", profit)
+	}
+
+	if assetList[1].Profit == nil || assetList[1].Profit.MedianNet != 0 || assetList[1].Profit.TargetProfit != 0 {
+		t.Errorf("got %+v, want only a target estimate without a median", assetList[1].Profit)
+	}
+	if assetList[2].Profit != nil {
+		t.Errorf("got %+v, want no estimate without a price", assetList[2].Profit)
+	}
+}
@@ -0,0 +1,69 @@
+package steam
+
+import (
+	"errors"
+
+	"eiffel65/float"
+	"eiffel65/image"
+)
+
+// Inspect looks up the float, pattern and screenshot of a single item from
+// its inspect link, naming it from the item schema.
+func (client *Client) Inspect(inspectURL string) (*SimpleAsset, error) {
+	if inspectURL == "" {
+		return nil, errors.New("no inspect link to look up")
+	}
+
+	simpleAsset := SimpleAsset{InspectURL: inspectURL}
+	err := client.inspect(&simpleAsset)
+	if err != nil {
+		return nil, err
+	}
+
+	simpleAsset.Name = simpleAsset.Float.WeaponType
+	if simpleAsset.Float.ItemName != "" {
+		simpleAsset.Name += " | " + simpleAsset.Float.ItemName
+	}
+	simpleAsset.Quality.Wear = wearOfFloat(simpleAsset.Float.FloatValue)
+
+	return &simpleAsset, nil
+}
+
+// inspect fills in the float, screenshot and pattern tier of an asset from
+// its inspect link.
+func (client *Client) inspect(simpleAsset *SimpleAsset) error {
+	client.floatThrottle.wait(client.FloatRateLimit)
+	assetFloat, floatURL, err := float.GetWith(client.httpClient(), client.FloatBaseURL, simpleAsset.InspectURL)
+	if err != nil {
+		return err
+	}
+
+	client.logger().Debug("requested float", "url", floatURL)
+
+	simpleAsset.Float = assetFloat.ItemInfo
+	client.fillFromSchema(&simpleAsset.Float)
+
+	screenshotURL, err := image.BuildURL(simpleAsset.Float.DefIndex, simpleAsset.Float.PaintIndex, simpleAsset.Float.PaintSeed, simpleAsset.InspectURL)
+	if err != nil {
+		client.logger().Warn("failed to get screenshot", "inspect_url", simpleAsset.InspectURL, "error", err)
+	}
+
+	simpleAsset.ScreenshotURL = screenshotURL
+	simpleAsset.RarityTier = PatternTier(simpleAsset.Float.DefIndex, simpleAsset.Float.PaintSeed)
+
+	return nil
+}
+
+// wearOfFloat is the wear a float value falls in, or "" for items without
+// wear.
+func wearOfFloat(floatValue float64) AssetWear {
+	if floatValue == 0 {
+		return ""
+	}
+	for wear, wearRange := range wearRanges {
+		if floatValue >= wearRange[0] && floatValue < wearRange[1] {
+			return wear
+		}
+	}
+	return battleScared
+}
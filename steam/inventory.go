@@ -0,0 +1,219 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	inventoryPath     string = "inventory"
+	inventoryPageSize int    = 2000
+	// steamID64Base is the least 64 bit Steam ID of an individual account.
+	steamID64Base uint64 = 76561197960265728
+)
+
+// InventoryPayload is a page of the public inventory of a Steam profile.
+// The assets only carry IDs, with what they are in the descriptions.
+type InventoryPayload struct {
+	Success             int                    `json:"success"`
+	Assets              []InventoryAsset       `json:"assets,omitempty"`
+	Descriptions        []InventoryDescription `json:"descriptions,omitempty"`
+	TotalInventoryCount int                    `json:"total_inventory_count,omitempty"`
+	MoreItems           int                    `json:"more_items,omitempty"`
+	LastAssetID         string                 `json:"last_assetid,omitempty"`
+	Error               string                 `json:"error,omitempty"`
+}
+
+// InventoryAsset is an item in an inventory.
+type InventoryAsset struct {
+	AppID      int    `json:"appid,omitempty"`
+	ContextID  string `json:"contextid,omitempty"`
+	AssetID    string `json:"assetid,omitempty"`
+	ClassID    string `json:"classid,omitempty"`
+	InstanceID string `json:"instanceid,omitempty"`
+	Amount     string `json:"amount,omitempty"`
+}
+
+// InventoryDescription describes every inventory item of a class and
+// instance.
+type InventoryDescription struct {
+	ClassID        string         `json:"classid,omitempty"`
+	InstanceID     string         `json:"instanceid,omitempty"`
+	IconURL        string         `json:"icon_url,omitempty"`
+	Name           string         `json:"name,omitempty"`
+	Type           string         `json:"type,omitempty"`
+	MarketName     string         `json:"market_name,omitempty"`
+	MarketHashName string         `json:"market_hash_name,omitempty"`
+	Tradable       int            `json:"tradable,omitempty"`
+	Marketable     int            `json:"marketable,omitempty"`
+	Commodity      int            `json:"commodity,omitempty"`
+	Actions        []MarketAction `json:"actions,omitempty"`
+	Tags           []Tag          `json:"tags,omitempty"`
+}
+
+// GetInventory looks up the public CS inventory of a Steam profile by its
+// 64 bit Steam ID, inspecting every item with an inspect link for its float,
+// pattern and screenshot. Run CheckForRarity on it to find the rare patterns.
+func (client *Client) GetInventory(steamID64 string) (*[]SimpleAsset, error) {
+	err := validateSteamID64(steamID64)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := []InventoryAsset{}
+	descriptions := map[string]InventoryDescription{}
+	startAssetID := ""
+	for {
+		page, err := client.getInventoryPage(steamID64, startAssetID)
+		if err != nil {
+			return nil, err
+		}
+
+		assets = append(assets, page.Assets...)
+		for _, description := range page.Descriptions {
+			descriptions[description.ClassID+"_"+description.InstanceID] = description
+		}
+
+		if page.MoreItems == 0 || page.LastAssetID == "" {
+			break
+		}
+		startAssetID = page.LastAssetID
+	}
+
+	simpleAssetList := []SimpleAsset{}
+	for _, asset := range assets {
+		description, ok := descriptions[asset.ClassID+"_"+asset.InstanceID]
+		if !ok {
+			client.logger().Warn("no description for inventory item", "asset_id", asset.AssetID, "class_id", asset.ClassID)
+			continue
+		}
+
+		simpleAsset := description.simpleAsset(client.CDNBaseURL)
+		simpleAsset.ID = asset.AssetID
+		simpleAsset.ClassID = asset.ClassID
+		simpleAsset.ContextID = asset.ContextID
+		simpleAsset.InstanceID = asset.InstanceID
+
+		for _, action := range description.Actions {
+			if action.Name == "Inspect in Game..." {
+				simpleAsset.InspectURL = inventoryInspectURL(steamID64, asset.AssetID, action.Link)
+			}
+		}
+
+		// Items without an inspect link, such as cases and stickers, have no
+		// float to look up, and those that fail are kept without one.
+		if simpleAsset.InspectURL != "" {
+			err := client.inspect(&simpleAsset)
+			if err != nil {
+				client.logger().Warn("failed to inspect inventory item", "name", simpleAsset.Name, "asset_id", simpleAsset.ID, "error", err)
+			}
+		}
+
+		simpleAssetList = append(simpleAssetList, simpleAsset)
+	}
+
+	client.logger().Debug("got inventory", "steam_id", steamID64, "items", len(simpleAssetList))
+
+	return &simpleAssetList, nil
+}
+
+// getInventoryPage fetches a page of an inventory, from the start of it if
+// startAssetID is empty.
+func (client *Client) getInventoryPage(steamID64, startAssetID string) (*InventoryPayload, error) {
+	inventoryURL, err := url.Parse(fmt.Sprintf("%s/%s/%s/%s/2", client.MarketBaseURL, inventoryPath, steamID64, csgoAppID))
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("l", "english")
+	params.Add("count", strconv.Itoa(inventoryPageSize))
+	if startAssetID != "" {
+		params.Add("start_assetid", startAssetID)
+	}
+	inventoryURL.RawQuery = params.Encode()
+
+	client.logger().Debug("requesting inventory", "url", inventoryURL.String())
+
+	response, err := client.getMarket(inventoryURL.String())
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	// The community answers a private inventory with a 403.
+	if response.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("the inventory of %s is private", steamID64)
+	}
+	err = checkResponseStatus(response.StatusCode)
+	if err != nil {
+		return nil, err
+	}
+
+	inventoryPayload := InventoryPayload{}
+	err = json.NewDecoder(response.Body).Decode(&inventoryPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode inventory: %s", err)
+	}
+
+	if inventoryPayload.Success != 1 {
+		if inventoryPayload.Error != "" {
+			return nil, fmt.Errorf("failed to get the inventory of %s: %s", steamID64, inventoryPayload.Error)
+		}
+		return nil, fmt.Errorf("failed to get the inventory of %s", steamID64)
+	}
+
+	return &inventoryPayload, nil
+}
+
+// simpleAsset fills out the parts of a simple asset every item of the
+// description shares.
+func (description InventoryDescription) simpleAsset(cdnBaseURL string) SimpleAsset {
+	name := description.MarketHashName
+	if name == "" {
+		name = description.MarketName
+	}
+	wear := parseMarketNameWear(name)
+
+	simpleAsset := SimpleAsset{
+		Name:        name,
+		EncodedName: url.PathEscape(name),
+		Variant: AssetVariant{
+			Wear:     wear,
+			StatTrak: strings.Contains(name, statTrak),
+			Souvenir: strings.HasPrefix(name, souvenir+" "),
+		}.String(),
+		Type:       guessAssetType(name, wear),
+		Marketable: description.Marketable == 1,
+		Quality: AssetQuality{
+			Wear: wear,
+			Type: description.Type,
+		},
+	}
+	if description.IconURL != "" {
+		simpleAsset.IconURL = cdnBaseURL + description.IconURL
+	}
+	return simpleAsset
+}
+
+// inventoryInspectURL fills in the placeholders the community leaves in the
+// inspect links of inventory items, e.g.
+// steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20S%owner_steamid%A%assetid%D4775570208539014329
+// becomes ...%20S76561198299749713A7013114583D4775570208539014329.
+func inventoryInspectURL(steamID64, assetID, rawInspectURL string) string {
+	return strings.NewReplacer("%owner_steamid%", steamID64, "%assetid%", assetID).Replace(rawInspectURL)
+}
+
+// validateSteamID64 checks a Steam ID is the 64 bit form of an individual
+// account, such as 76561198299749713, rather than a vanity name.
+func validateSteamID64(steamID64 string) error {
+	id, err := strconv.ParseUint(steamID64, 10, 64)
+	if err != nil || id < steamID64Base || len(steamID64) != 17 {
+		return fmt.Errorf("invalid Steam ID %q, expected the 17 digit SteamID64 of a profile", steamID64)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package steam
+
+import (
+	"strings"
+	"testing"
+)
+
+const testSteamID64 = "76561198299749713"
+
+func TestGetInventory(t *testing.T) {
+	fake := &fakeSteam{Inventory: 3, FloatFails: map[string]bool{"1002": true}}
+	client := fake.client(t)
+	client.CDNBaseURL = "https://cdn.test/"
+
+	assetList, err := client.GetInventory(testSteamID64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*assetList) != 4 {
+		t.Fatalf("got %d items, want 4", len(*assetList))
+	}
+
+	// The three skins and the case come over two pages.
+	inventoryRequests := []string{}
+	for _, request := range fake.Requests() {
+		if strings.HasPrefix(request, "/inventory/") {
+			inventoryRequests = append(inventoryRequests, request)
+		}
+	}
+	wantRequests := []string{
+		"/inventory/" + testSteamID64 + "/730/2?count=2000&l=english",
+		"/inventory/" + testSteamID64 + "/730/2?count=2000&l=english&start_assetid=1002",
+	}
+	if strings.Join(inventoryRequests, "\n") != strings.Join(wantRequests, "\n") {
+		t.Errorf("got requests %q, want %q", inventoryRequests, wantRequests)
+	}
+
+	first := (*assetList)[0]
+	if first.ID != "1001" || first.ClassID != "310776560" || first.Name != "AK-47 | Case Hardened (Field-Tested)" ||
+		first.Type != weaponAsset || first.Quality != (AssetQuality{Wear: fieldTested, Type: "Classified Rifle"}) ||
+		first.IconURL != "https://cdn.test/ak47" || !first.Marketable {
+		t.Errorf("got first item %+v", first)
+	}
+	wantInspectURL := "steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20S" + testSteamID64 + "A1001D7002"
+	if first.InspectURL != wantInspectURL {
+		t.Errorf("got inspect link %q, want %q", first.InspectURL, wantInspectURL)
+	}
+	if first.Float.PaintSeed != 1 || first.ScreenshotURL == "" {
+		t.Errorf("expected the first item to be inspected, got %+v", first.Float)
+	}
+
+	// The skin the float API failed on is kept, without its float.
+	if failed := (*assetList)[1]; failed.ID != "1002" || failed.Float.PaintSeed != 0 {
+		t.Errorf("got uninspected item %+v", failed)
+	}
+	if third := (*assetList)[2]; third.Float.PaintSeed != 3 {
+		t.Errorf("expected the item after the failure to be inspected, got %+v", third)
+	}
+
+	// The case has nothing to inspect.
+	if container := (*assetList)[3]; container.Name != "Revolution Case" || container.InspectURL != "" || container.Float.PaintSeed != 0 {
+		t.Errorf("got case %+v", container)
+	}
+}
+
+func TestGetInventoryPrivate(t *testing.T) {
+	fake := &fakeSteam{PrivateInventory: true}
+	client := fake.client(t)
+
+	_, err := client.GetInventory(testSteamID64)
+	if err == nil || !strings.Contains(err.Error(), "private") {
+		t.Errorf("got %v, want a private inventory error", err)
+	}
+}
+
+func TestValidateSteamID64(t *testing.T) {
+	tests := []struct {
+		steamID64 string
+		valid     bool
+	}{
+		{testSteamID64, true},
+		{"76561197960265728", true},
+		{"gabelogannewell", false},
+		{"7656119829974971", false},
+		{"765611982997497130", false},
+		{"12345678901234567", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		err := validateSteamID64(test.steamID64)
+		if (err == nil) != test.valid {
+			t.Errorf("%q: got %v, want valid %t", test.steamID64, err, test.valid)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseAssetType converts a user supplied item kind into an AssetType that
+// formatMarketName knows how to name.
+func ParseAssetType(kind string) (AssetType, error) {
+	switch assetType := AssetType(strings.ToLower(strings.TrimSpace(kind))); assetType {
+	case weaponAsset, knifeAsset, glovesAsset, stickerAsset, agentAsset, caseAsset:
+		return assetType, nil
+	case "":
+		return weaponAsset, nil
+	}
+	return "", fmt.Errorf("unknown item type %q, expected one of weapon, knife, gloves, sticker, agent or case", kind)
+}
+
+// HasWear reports whether items of the type come in wears, as weapons,
+// knives and gloves do.
+func (assetType AssetType) HasWear() bool {
+	switch assetType {
+	case weaponAsset, knifeAsset, glovesAsset:
+		return true
+	}
+	return false
+}
+
+// formatMarketName creates the Steam market-searchable name for an asset.
+//
+// Each kind of item follows its own naming rules on the market:
+//
+//	weapon:        [StatTrak™ |Souvenir ]AK-47 | Case Hardened (Field-Tested)
+//	knife:         ★ [StatTrak™ ]Karambit | Case Hardened (Field-Tested)
+//	vanilla knife: ★ [StatTrak™ ]Karambit
+//	gloves:        ★ Sport Gloves | Vice (Field-Tested)
+//	sticker:       Sticker | Crown (Foil)
+//	agent:         Sir Bloody Miami Darryl | The Professionals
+//	case:          Chroma 2 Case
+func formatMarketName(baseName string, assetType AssetType, wear AssetWear, isStatTrak, isSouvenir bool) (string, error) {
+	baseName, typedStatTrak, typedSouvenir := trimMarketPrefixes(baseName)
+	isStatTrak = isStatTrak || typedStatTrak
+	isSouvenir = isSouvenir || typedSouvenir
+	if baseName == "" {
+		return "", errors.New("an item name is required")
+	}
+
+	if isStatTrak && isSouvenir {
+		return "", errors.New("an item cannot be both StatTrak and Souvenir")
+	}
+
+	// Only weapons and knives carry a paint with a wear, so finished skins are
+	// always named "Weapon | Paint".
+	isPainted := strings.Contains(baseName, "|")
+
+	switch assetType {
+	case weaponAsset:
+		if wear == "" {
+			return "", fmt.Errorf("%s needs a wear", baseName)
+		}
+
+		marketName := ""
+		if isStatTrak {
+			marketName = statTrak + " "
+		}
+		if isSouvenir {
+			marketName = souvenir + " "
+		}
+		return marketName + baseName + " (" + string(wear) + ")", nil
+
+	case knifeAsset:
+		if isSouvenir {
+			return "", errors.New("knives cannot be Souvenir")
+		}
+
+		marketName := starPrefix + " "
+		if isStatTrak {
+			marketName += statTrak + " "
+		}
+		marketName += baseName
+
+		// Vanilla knives have no paint and so no wear.
+		if !isPainted {
+			if wear != "" {
+				return "", fmt.Errorf("vanilla %s has no wear, leave it unset", baseName)
+			}
+			return marketName, nil
+		}
+
+		if wear == "" {
+			return "", fmt.Errorf("%s needs a wear", baseName)
+		}
+		return marketName + " (" + string(wear) + ")", nil
+
+	case glovesAsset:
+		if isStatTrak || isSouvenir {
+			return "", errors.New("gloves cannot be StatTrak or Souvenir")
+		}
+		if wear == "" {
+			return "", fmt.Errorf("%s needs a wear", baseName)
+		}
+		return starPrefix + " " + baseName + " (" + string(wear) + ")", nil
+
+	case stickerAsset:
+		if err := checkUnwearable(assetType, wear, isStatTrak, isSouvenir); err != nil {
+			return "", err
+		}
+		if !strings.HasPrefix(baseName, stickerPrefix) {
+			baseName = stickerPrefix + " " + baseName
+		}
+		return baseName, nil
+
+	case agentAsset, caseAsset:
+		if err := checkUnwearable(assetType, wear, isStatTrak, isSouvenir); err != nil {
+			return "", err
+		}
+		return baseName, nil
+	}
+
+	return "", fmt.Errorf("unsupported item type %q", assetType)
+}
+
+// checkUnwearable rejects wear, StatTrak and Souvenir options for items that
+// are only ever listed under their plain name.
+func checkUnwearable(assetType AssetType, wear AssetWear, isStatTrak, isSouvenir bool) error {
+	if wear != "" {
+		return fmt.Errorf("%s items have no wear, leave it unset", assetType)
+	}
+	if isStatTrak || isSouvenir {
+		return fmt.Errorf("%s items cannot be StatTrak or Souvenir", assetType)
+	}
+	return nil
+}
+
+// trimMarketPrefixes removes any star, StatTrak or Souvenir prefix the user has
+// typed themselves so they are not added twice, reporting which were present.
+func trimMarketPrefixes(baseName string) (string, bool, bool) {
+	baseName = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(baseName), starPrefix))
+
+	isStatTrak := strings.HasPrefix(baseName, statTrak+" ")
+	baseName = strings.TrimSpace(strings.TrimPrefix(baseName, statTrak+" "))
+
+	isSouvenir := strings.HasPrefix(baseName, souvenir+" ")
+	baseName = strings.TrimSpace(strings.TrimPrefix(baseName, souvenir+" "))
+
+	return baseName, isStatTrak, isSouvenir
+}
@@ -0,0 +1,100 @@
+package steam
+
+import "testing"
+
+func TestFormatMarketName(t *testing.T) {
+	tests := []struct {
+		name       string
+		baseName   string
+		assetType  AssetType
+		wear       AssetWear
+		isStatTrak bool
+		isSouvenir bool
+		want       string
+	}{
+		{"weapon", "AK-47 | Case Hardened", weaponAsset, fieldTested, false, false, "AK-47 | Case Hardened (Field-Tested)"},
+		{"stattrak weapon", "AK-47 | Case Hardened", weaponAsset, factoryNew, true, false, "StatTrak™ AK-47 | Case Hardened (Factory New)"},
+		{"souvenir weapon", "AWP | Dragon Lore", weaponAsset, factoryNew, false, true, "Souvenir AWP | Dragon Lore (Factory New)"},
+		{"typed souvenir prefix", "Souvenir AWP | Dragon Lore", weaponAsset, factoryNew, false, false, "Souvenir AWP | Dragon Lore (Factory New)"},
+		{"knife", "Karambit | Case Hardened", knifeAsset, fieldTested, false, false, "★ Karambit | Case Hardened (Field-Tested)"},
+		{"stattrak knife", "Karambit | Case Hardened", knifeAsset, wellWorn, true, false, "★ StatTrak™ Karambit | Case Hardened (Well-Worn)"},
+		{"typed star prefix", "★ Falchion Knife | Case Hardened", knifeAsset, minimalWear, false, false, "★ Falchion Knife | Case Hardened (Minimal Wear)"},
+		{"typed star and stattrak prefix", "★ StatTrak™ Falchion Knife | Case Hardened", knifeAsset, minimalWear, false, false, "★ StatTrak™ Falchion Knife | Case Hardened (Minimal Wear)"},
+		{"vanilla knife", "Karambit", knifeAsset, "", false, false, "★ Karambit"},
+		{"stattrak vanilla knife", "Karambit", knifeAsset, "", true, false, "★ StatTrak™ Karambit"},
+		{"gloves", "Sport Gloves | Vice", glovesAsset, fieldTested, false, false, "★ Sport Gloves | Vice (Field-Tested)"},
+		{"sticker", "Crown (Foil)", stickerAsset, "", false, false, "Sticker | Crown (Foil)"},
+		{"sticker with prefix", "Sticker | Crown (Foil)", stickerAsset, "", false, false, "Sticker | Crown (Foil)"},
+		{"agent", "Sir Bloody Miami Darryl | The Professionals", agentAsset, "", false, false, "Sir Bloody Miami Darryl | The Professionals"},
+		{"case", "Chroma 2 Case", caseAsset, "", false, false, "Chroma 2 Case"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := formatMarketName(test.baseName, test.assetType, test.wear, test.isStatTrak, test.isSouvenir)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFormatMarketNameInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		baseName   string
+		assetType  AssetType
+		wear       AssetWear
+		isStatTrak bool
+		isSouvenir bool
+	}{
+		{"empty name", " ", weaponAsset, fieldTested, false, false},
+		{"weapon without wear", "AK-47 | Redline", weaponAsset, "", false, false},
+		{"stattrak and souvenir", "AK-47 | Redline", weaponAsset, fieldTested, true, true},
+		{"souvenir knife", "Karambit | Fade", knifeAsset, factoryNew, false, true},
+		{"painted knife without wear", "Karambit | Fade", knifeAsset, "", false, false},
+		{"vanilla knife with wear", "Karambit", knifeAsset, fieldTested, false, false},
+		{"stattrak gloves", "Sport Gloves | Vice", glovesAsset, fieldTested, true, false},
+		{"gloves without wear", "Sport Gloves | Vice", glovesAsset, "", false, false},
+		{"sticker with wear", "Crown (Foil)", stickerAsset, fieldTested, false, false},
+		{"stattrak agent", "Sir Bloody Miami Darryl | The Professionals", agentAsset, "", true, false},
+		{"souvenir case", "Chroma 2 Case", caseAsset, "", false, true},
+		{"unknown type", "Name Tag", tagAsset, "", false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := formatMarketName(test.baseName, test.assetType, test.wear, test.isStatTrak, test.isSouvenir)
+			if err == nil {
+				t.Errorf("expected an error, got %q", got)
+			}
+		})
+	}
+}
+
+func TestParseAssetType(t *testing.T) {
+	for kind, want := range map[string]AssetType{
+		"":        weaponAsset,
+		"weapon":  weaponAsset,
+		"Knife":   knifeAsset,
+		" gloves": glovesAsset,
+		"sticker": stickerAsset,
+		"agent":   agentAsset,
+		"case":    caseAsset,
+	} {
+		got, err := ParseAssetType(kind)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", kind, err)
+		}
+		if got != want {
+			t.Errorf("%q: got %q, want %q", kind, got, want)
+		}
+	}
+
+	if _, err := ParseAssetType("skin"); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+}
@@ -0,0 +1,167 @@
+package steam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// priceOverviewPayload is the response from the price overview endpoint.
+type priceOverviewPayload struct {
+	Success     bool   `json:"success"`
+	LowestPrice string `json:"lowest_price,omitempty"`
+	MedianPrice string `json:"median_price,omitempty"`
+	Volume      string `json:"volume,omitempty"`
+}
+
+// GetPriceOverview returns the lowest and median price of an item on the
+// market, looked up by its market hash name, from the price cache if it has
+// it.
+func (client *Client) GetPriceOverview(marketHashName string) (*AssetValue, error) {
+	if client.PriceCache != nil {
+		if assetValue, ok := client.PriceCache.get(client.Currency, marketHashName); ok {
+			return assetValue, nil
+		}
+	}
+
+	priceOverviewURL, err := url.Parse(fmt.Sprintf("%s/%s", client.MarketBaseURL, priceOverviewPath))
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("appid", csgoAppID)
+	params.Add("currency", client.Currency)
+	params.Add("market_hash_name", marketHashName)
+	priceOverviewURL.RawQuery = params.Encode()
+
+	client.logger().Debug("requesting price overview", "url", priceOverviewURL.String())
+
+	response, err := client.getMarket(priceOverviewURL.String())
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	err = checkResponseStatus(response.StatusCode)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := priceOverviewPayload{}
+	err = json.NewDecoder(response.Body).Decode(&payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if !payload.Success {
+		return nil, fmt.Errorf("no price overview for %s", marketHashName)
+	}
+
+	assetValue := AssetValue{
+		Currency:    client.Currency,
+		LowestPrice: payload.LowestPrice,
+		MedianPrice: payload.MedianPrice,
+		Volume:      payload.Volume,
+	}
+	if client.PriceCache != nil {
+		client.PriceCache.put(client.Currency, marketHashName, assetValue)
+	}
+	return &assetValue, nil
+}
+
+// PriceAssets fills in the market value of each listing from the price
+// overview, looking each item up once.
+func (client *Client) PriceAssets(assetList []SimpleAsset) {
+	marketValues := map[string]*AssetValue{}
+	for i, asset := range assetList {
+		marketValue, ok := marketValues[asset.Name]
+		if !ok {
+			var err error
+			marketValue, err = client.GetPriceOverview(asset.Name)
+			if err != nil {
+				client.logger().Warn("failed to get price overview", "name", asset.Name, "error", err)
+			}
+			marketValues[asset.Name] = marketValue
+		}
+
+		if marketValue != nil {
+			assetList[i].MarketValue = *marketValue
+		}
+	}
+}
+
+// PriceStickers fills in the combined market value of the stickers applied
+// to each listing, looking each sticker up once.
+func (client *Client) PriceStickers(assetList []SimpleAsset) {
+	stickerPrices := map[string]float64{}
+	for i, asset := range assetList {
+		stickerValue := 0.0
+		for _, sticker := range asset.Float.Stickers {
+			if sticker.Name == "" {
+				continue
+			}
+
+			price, ok := stickerPrices[sticker.Name]
+			if !ok {
+				marketValue, err := client.GetPriceOverview(stickerPrefix + " " + sticker.Name)
+				if err != nil {
+					client.logger().Warn("failed to get price overview for sticker", "sticker", sticker.Name, "error", err)
+				} else {
+					price, _ = marketValue.Price()
+				}
+				stickerPrices[sticker.Name] = price
+			}
+
+			stickerValue += price
+		}
+		assetList[i].StickerValue = stickerValue
+	}
+}
+
+// Price is the median price of the item, falling back to the lowest price
+// when there have been no recent sales.
+func (value AssetValue) Price() (float64, error) {
+	if value.MedianPrice != "" {
+		return parsePrice(value.MedianPrice)
+	}
+	return parsePrice(value.LowestPrice)
+}
+
+// parsePrice reads a price formatted by the market such as "£1,234.56",
+// "1.234,56€" or "$0.03".
+func parsePrice(price string) (float64, error) {
+	digits := strings.Builder{}
+	for _, r := range price {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' {
+			digits.WriteRune(r)
+		}
+	}
+	number := strings.Trim(digits.String(), ".,")
+	if number == "" {
+		return 0, errors.New("no price")
+	}
+
+	// Whichever separator comes last is the decimal point when both are
+	// used. When only one is used it separates thousands if it appears more
+	// than once or is followed by exactly three digits.
+	decimal := strings.LastIndexAny(number, ".,")
+	oneSeparator := strings.Contains(number, ".") != strings.Contains(number, ",")
+	if decimal >= 0 && oneSeparator {
+		separator := number[decimal : decimal+1]
+		if strings.Count(number, separator) > 1 || len(number)-decimal-1 == 3 {
+			decimal = -1
+		}
+	}
+
+	whole, fraction := number, "0"
+	if decimal >= 0 {
+		whole, fraction = number[:decimal], number[decimal+1:]
+	}
+	whole = strings.NewReplacer(".", "", ",", "").Replace(whole)
+
+	return strconv.ParseFloat(whole+"."+fraction, 64)
+}
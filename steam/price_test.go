@@ -0,0 +1,42 @@
+package steam
+
+import "testing"
+
+func TestParsePrice(t *testing.T) {
+	for price, want := range map[string]float64{
+		"£1.23":      1.23,
+		"$0.03":      0.03,
+		"£1,234.56":  1234.56,
+		"1.234,56€":  1234.56,
+		"12,34€":     12.34,
+		"1,234":      1234,
+		"1.234.567":  1234567,
+		"¥ 1234":     1234,
+		"12,--€":     12,
+		"CDN$ 10.50": 10.5,
+	} {
+		got, err := parsePrice(price)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", price, err)
+		}
+		if got != want {
+			t.Errorf("%q: got %v, want %v", price, got, want)
+		}
+	}
+
+	if _, err := parsePrice("free"); err == nil {
+		t.Error("expected an error without a number")
+	}
+}
+
+func TestAssetValuePrice(t *testing.T) {
+	price, err := AssetValue{LowestPrice: "£2.00", MedianPrice: "£1.50"}.Price()
+	if err != nil || price != 1.5 {
+		t.Errorf("got %v, %v, want the median", price, err)
+	}
+
+	price, err = AssetValue{LowestPrice: "£2.00"}.Price()
+	if err != nil || price != 2 {
+		t.Errorf("got %v, %v, want the lowest price", price, err)
+	}
+}
@@ -0,0 +1,122 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PriceCache keeps price overviews for a while, so items valued again soon
+// after do not go back to the market, which only allows a few lookups a
+// minute. It can be saved to a file to keep them between runs.
+type PriceCache struct {
+	// TTL is how long a price is kept, forever if it is not positive.
+	TTL time.Duration
+
+	mu      sync.Mutex
+	path    string
+	entries map[string]cachedPrice
+}
+
+// cachedPrice is a price overview and when it was looked up.
+type cachedPrice struct {
+	Value   AssetValue `json:"value"`
+	Fetched time.Time  `json:"fetched"`
+}
+
+// NewPriceCache creates a cache kept in memory only.
+func NewPriceCache(ttl time.Duration) *PriceCache {
+	return &PriceCache{TTL: ttl, entries: map[string]cachedPrice{}}
+}
+
+// LoadPriceCache reads the cache saved at the path, starting an empty one if
+// there is none yet. Save writes it back.
+func LoadPriceCache(path string, ttl time.Duration) (*PriceCache, error) {
+	cache := NewPriceCache(ttl)
+	cache.path = path
+
+	cacheJSON, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price cache: %s", err)
+	}
+
+	err = json.Unmarshal(cacheJSON, &cache.entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price cache %s: %s", path, err)
+	}
+	// A file holding null unmarshals to a nil map.
+	if cache.entries == nil {
+		cache.entries = map[string]cachedPrice{}
+	}
+	return cache, nil
+}
+
+// Save writes the prices that have not expired back to the file the cache
+// was loaded from, if it was.
+func (cache *PriceCache) Save() error {
+	if cache.path == "" {
+		return nil
+	}
+
+	cache.mu.Lock()
+	now := time.Now()
+	for key, entry := range cache.entries {
+		if cache.expired(entry, now) {
+			delete(cache.entries, key)
+		}
+	}
+	cacheJSON, err := json.Marshal(cache.entries)
+	cache.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal price cache: %s", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(cache.path), 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create price cache directory: %s", err)
+	}
+	err = os.WriteFile(cache.path, cacheJSON, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to save price cache: %s", err)
+	}
+	return nil
+}
+
+// get returns the cached price of an item in a currency, if it has not
+// expired.
+func (cache *PriceCache) get(currency, marketHashName string) (*AssetValue, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[priceCacheKey(currency, marketHashName)]
+	if !ok || cache.expired(entry, time.Now()) {
+		return nil, false
+	}
+	value := entry.Value
+	return &value, true
+}
+
+// put caches the price of an item in a currency.
+func (cache *PriceCache) put(currency, marketHashName string, value AssetValue) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.entries[priceCacheKey(currency, marketHashName)] = cachedPrice{Value: value, Fetched: time.Now()}
+}
+
+// expired reports whether a cached price is too old to use.
+func (cache *PriceCache) expired(entry cachedPrice, now time.Time) bool {
+	return cache.TTL > 0 && now.Sub(entry.Fetched) > cache.TTL
+}
+
+// priceCacheKey keys prices by currency as well as name, as they are
+// converted by the market.
+func priceCacheKey(currency, marketHashName string) string {
+	return currency + "/" + marketHashName
+}
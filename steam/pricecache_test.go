@@ -0,0 +1,76 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPriceCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "prices.json")
+	cache, err := LoadPriceCache(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := AssetValue{Currency: "2", MedianPrice: "£12.50"}
+	cache.put("2", "AK-47 | Redline (Field-Tested)", value)
+	cache.entries[priceCacheKey("2", "AWP | Asiimov (Field-Tested)")] = cachedPrice{Value: value, Fetched: time.Now().Add(-2 * time.Hour)}
+	err = cache.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadPriceCache(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		currency string
+		name     string
+		want     bool
+	}{
+		{"2", "AK-47 | Redline (Field-Tested)", true},
+		{"3", "AK-47 | Redline (Field-Tested)", false},
+		{"2", "AWP | Asiimov (Field-Tested)", false},
+		{"2", "M4A4 | Howl (Field-Tested)", false},
+	}
+	for _, test := range tests {
+		got, ok := loaded.get(test.currency, test.name)
+		if ok != test.want || (ok && *got != value) {
+			t.Errorf("%s in %s: got %v, %t, want cached %t", test.name, test.currency, got, ok, test.want)
+		}
+	}
+	if len(loaded.entries) != 1 {
+		t.Errorf("expected the expired price to be left out when saving, got %d entries", len(loaded.entries))
+	}
+}
+
+func TestPriceCacheNull(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	err := os.WriteFile(path, []byte("null"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := LoadPriceCache(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := AssetValue{Currency: "2", MedianPrice: "£12.50"}
+	cache.put("2", "AK-47 | Redline (Field-Tested)", value)
+	err = cache.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadPriceCache(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := loaded.get("2", "AK-47 | Redline (Field-Tested)")
+	if !ok || *got != value {
+		t.Errorf("got %v, %t, want %v cached", got, ok, value)
+	}
+}
@@ -0,0 +1,56 @@
+package steam
+
+import (
+	"net/http"
+	"testing"
+
+	"eiffel65/replay"
+)
+
+// replayClient answers every request from the fixtures in testdata/replay,
+// recorded from three Field-Tested AK-47 | Case Hardened listings.
+func replayClient(t *testing.T) *Client {
+	t.Helper()
+	transport, err := replay.NewReplayer("testdata/replay")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient("")
+	client.HTTPClient = &http.Client{Transport: transport}
+	return client
+}
+
+func TestReplayPipeline(t *testing.T) {
+	client := replayClient(t)
+
+	assetList, err := client.NewAsset("AK-47 | Case Hardened", weaponAsset, 3, 3, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*assetList) != 3 {
+		t.Fatalf("got %d listings, want 3", len(*assetList))
+	}
+
+	first := (*assetList)[0]
+	if first.ListingID != "4209238481020419851" || first.ListingTotalPrice != "479.98" || first.Float.PaintSeed != 661 || first.Float.FloatValue != 0.26094716787338257 {
+		t.Errorf("got first listing %+v", first)
+	}
+	if first.Quality.Type != "Classified Rifle" || first.ScreenshotURL == "" {
+		t.Errorf("got type %q and screenshot %q", first.Quality.Type, first.ScreenshotURL)
+	}
+
+	client.PriceAssets(*assetList)
+	if got := (*assetList)[1].MarketValue.LowestPrice; got != "£13.04" {
+		t.Errorf("got lowest price %q, want £13.04", got)
+	}
+
+	rare := CheckForRarity(*assetList)
+	if len(rare) != 1 {
+		t.Fatalf("got rare listings %+v, want only the 661", rare)
+	}
+	for _, asset := range rare {
+		if asset.Float.PaintSeed != 661 || asset.RarityTier != 1 {
+			t.Errorf("got rare listing %+v", asset)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package steam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Rule is a user defined condition for a notable listing. Every field that is
+// set must match, and unset fields match anything.
+type Rule struct {
+	Name string `json:"name" yaml:"name"`
+	// Item matches listings whose market name contains the text, ignoring case.
+	Item  string      `json:"item,omitempty" yaml:"item,omitempty"`
+	Wears []AssetWear `json:"wears,omitempty" yaml:"wears,omitempty"`
+	// MinFloat and MaxFloat bound the float value, inclusive.
+	MinFloat float64 `json:"min_float,omitempty" yaml:"min_float,omitempty"`
+	MaxFloat float64 `json:"max_float,omitempty" yaml:"max_float,omitempty"`
+	Seeds    []int   `json:"seeds,omitempty" yaml:"seeds,omitempty"`
+	// MaxTier matches patterns of this PatternTier or rarer.
+	MaxTier int `json:"max_tier,omitempty" yaml:"max_tier,omitempty"`
+	// MinStickerValue is the least combined value of applied stickers.
+	MinStickerValue float64 `json:"min_sticker_value,omitempty" yaml:"min_sticker_value,omitempty"`
+	// MaxPrice is the most the listing can cost including fees.
+	MaxPrice float64 `json:"max_price,omitempty" yaml:"max_price,omitempty"`
+	// MinDiscount is how far below the median price, as a percentage, the
+	// listing must be.
+	MinDiscount float64 `json:"min_discount,omitempty" yaml:"min_discount,omitempty"`
+	// MinDealScore is the least DealScore the listing must have.
+	MinDealScore float64 `json:"min_deal_score,omitempty" yaml:"min_deal_score,omitempty"`
+	// MinComparableDiscount is how far below listings with a similar float,
+	// as a percentage, the listing must be.
+	MinComparableDiscount float64 `json:"min_comparable_discount,omitempty" yaml:"min_comparable_discount,omitempty"`
+	// MinProfit is the least net profit, after fees, from relisting at the
+	// market median.
+	MinProfit float64 `json:"min_profit,omitempty" yaml:"min_profit,omitempty"`
+	// Notify names the notifiers to alert about matches, or every notifier
+	// when empty.
+	Notify []string `json:"notify,omitempty" yaml:"notify,omitempty"`
+}
+
+// RuleMatch is a listing that matched a rule.
+type RuleMatch struct {
+	Rule  string      `json:"rule"`
+	Asset SimpleAsset `json:"asset"`
+}
+
+// LoadRules reads a JSON list of rules from a file.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []Rule{}
+	err = json.Unmarshal(data, &rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rules in %s: %s", path, err)
+	}
+
+	err = ValidateRules(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// ValidateRules checks every rule is named uniquely and is satisfiable.
+func ValidateRules(rules []Rule) error {
+	names := map[string]bool{}
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return errors.New("every rule needs a name")
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule %q is defined twice", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if rule.MaxFloat != 0 && rule.MinFloat > rule.MaxFloat {
+			return fmt.Errorf("rule %q has a min_float above its max_float", rule.Name)
+		}
+		for _, wear := range rule.Wears {
+			if _, ok := wearRanges[wear]; !ok {
+				return fmt.Errorf("rule %q has an unknown wear %q", rule.Name, wear)
+			}
+		}
+	}
+	return nil
+}
+
+// EvaluateRules checks every listing against every rule, returning a match
+// for each rule a listing satisfies.
+func EvaluateRules(rules []Rule, assetList []SimpleAsset) []RuleMatch {
+	matches := []RuleMatch{}
+	for _, asset := range assetList {
+		for _, rule := range rules {
+			if rule.Match(asset) {
+				matches = append(matches, RuleMatch{Rule: rule.Name, Asset: asset})
+			}
+		}
+	}
+	return matches
+}
+
+// NeedsMarketValue reports whether any rule compares against the median
+// price, directly or through the deal score or profit, so listings must be
+// priced with PriceAssets first.
+func NeedsMarketValue(rules []Rule) bool {
+	for _, rule := range rules {
+		if rule.MinDiscount != 0 || rule.MinDealScore != 0 || rule.MinProfit != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsStickerValue reports whether any rule looks at sticker values, so
+// listings must be priced with PriceStickers first.
+func NeedsStickerValue(rules []Rule) bool {
+	for _, rule := range rules {
+		if rule.MinStickerValue != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether a listing satisfies the rule.
+func (rule Rule) Match(asset SimpleAsset) bool {
+	if rule.Item != "" && !strings.Contains(strings.ToLower(asset.Name), strings.ToLower(rule.Item)) {
+		return false
+	}
+
+	if len(rule.Wears) > 0 && !containsWear(rule.Wears, asset.Quality.Wear) {
+		return false
+	}
+
+	if rule.MinFloat != 0 && asset.Float.FloatValue < rule.MinFloat {
+		return false
+	}
+	if rule.MaxFloat != 0 && (asset.Float.FloatValue == 0 || asset.Float.FloatValue > rule.MaxFloat) {
+		return false
+	}
+
+	if len(rule.Seeds) > 0 && !containsSeed(rule.Seeds, asset.Float.PaintSeed) {
+		return false
+	}
+
+	if rule.MaxTier != 0 && (asset.RarityTier == 0 || asset.RarityTier > rule.MaxTier) {
+		return false
+	}
+
+	if rule.MinStickerValue != 0 && asset.StickerValue < rule.MinStickerValue {
+		return false
+	}
+
+	price, err := strconv.ParseFloat(asset.ListingTotalPrice, 64)
+	if rule.MaxPrice != 0 && (err != nil || price > rule.MaxPrice) {
+		return false
+	}
+
+	if rule.MinDiscount != 0 {
+		median, medianErr := asset.MarketValue.Price()
+		if err != nil || medianErr != nil || median == 0 {
+			return false
+		}
+		if (median-price)/median*100 < rule.MinDiscount {
+			return false
+		}
+	}
+
+	if rule.MinDealScore != 0 && (asset.Deal == nil || asset.Deal.Score < rule.MinDealScore) {
+		return false
+	}
+
+	if rule.MinComparableDiscount != 0 && (asset.Deal == nil || asset.Deal.Comparables == 0 || asset.Deal.ComparableDiscount < rule.MinComparableDiscount) {
+		return false
+	}
+
+	if rule.MinProfit != 0 && (asset.Profit == nil || asset.Profit.MedianNet == 0 || asset.Profit.MedianProfit < rule.MinProfit) {
+		return false
+	}
+
+	return true
+}
+
+// containsWear checks whether a wear is in a list.
+func containsWear(wears []AssetWear, wear AssetWear) bool {
+	for _, w := range wears {
+		if w == wear {
+			return true
+		}
+	}
+	return false
+}
+
+// containsSeed checks whether a seed is in a list.
+func containsSeed(seeds []int, seed int) bool {
+	for _, s := range seeds {
+		if s == seed {
+			return true
+		}
+	}
+	return false
+}
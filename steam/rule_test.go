@@ -0,0 +1,133 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"eiffel65/float"
+)
+
+var ruleTestAsset = SimpleAsset{
+	ID:                "1",
+	Name:              "AK-47 | Case Hardened (Field-Tested)",
+	ListingTotalPrice: "80.00",
+	RarityTier:        1,
+	StickerValue:      12.5,
+	Quality:           AssetQuality{Wear: fieldTested},
+	MarketValue:       AssetValue{MedianPrice: "£100.00"},
+	Profit:            &ProfitEstimate{Cost: 80, MedianNet: 86.97, MedianProfit: 6.97},
+	Float:             float.AssetFloat{DefIndex: 7, PaintSeed: 661, FloatValue: 0.21},
+}
+
+func TestRuleMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"empty rule", Rule{}, true},
+		{"item", Rule{Item: "case hardened"}, true},
+		{"other item", Rule{Item: "Redline"}, false},
+		{"wear", Rule{Wears: []AssetWear{minimalWear, fieldTested}}, true},
+		{"other wear", Rule{Wears: []AssetWear{factoryNew}}, false},
+		{"float range", Rule{MinFloat: 0.15, MaxFloat: 0.25}, true},
+		{"float too high", Rule{MaxFloat: 0.2}, false},
+		{"float too low", Rule{MinFloat: 0.3}, false},
+		{"seed", Rule{Seeds: []int{151, 661}}, true},
+		{"other seed", Rule{Seeds: []int{151}}, false},
+		{"tier", Rule{MaxTier: 1}, true},
+		{"sticker value", Rule{MinStickerValue: 10}, true},
+		{"sticker value too low", Rule{MinStickerValue: 20}, false},
+		{"price", Rule{MaxPrice: 80}, true},
+		{"price too high", Rule{MaxPrice: 79.99}, false},
+		{"discount", Rule{MinDiscount: 20}, true},
+		{"discount too small", Rule{MinDiscount: 25}, false},
+		{"profit", Rule{MinProfit: 6.9}, true},
+		{"profit too small", Rule{MinProfit: 7}, false},
+		{"combined", Rule{Item: "AK-47", Seeds: []int{661}, MaxPrice: 100, MinDiscount: 10}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.rule.Match(ruleTestAsset); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRuleMatchMissingData(t *testing.T) {
+	asset := SimpleAsset{Name: "AK-47 | Case Hardened (Field-Tested)"}
+
+	for _, rule := range []Rule{{MaxFloat: 0.5}, {MaxTier: 3}, {MaxPrice: 100}, {MinDiscount: 1}, {MinDealScore: 1}, {MinComparableDiscount: 1}, {MinProfit: 1}} {
+		if rule.Match(asset) {
+			t.Errorf("%+v: expected no match without the data to check", rule)
+		}
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	rules := []Rule{
+		{Name: "blue gems", MaxTier: 1},
+		{Name: "cheap", MaxPrice: 50},
+		{Name: "any ak", Item: "AK-47"},
+	}
+	cheap := SimpleAsset{ID: "2", Name: "AK-47 | Redline (Field-Tested)", ListingTotalPrice: "10.00"}
+
+	matches := EvaluateRules(rules, []SimpleAsset{ruleTestAsset, cheap})
+
+	got := []string{}
+	for _, match := range matches {
+		got = append(got, match.Asset.ID+":"+match.Rule)
+	}
+	want := []string{"1:blue gems", "1:any ak", "2:cheap", "2:any ak"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+
+	if !NeedsMarketValue([]Rule{{MinDiscount: 5}}) || NeedsMarketValue(rules) {
+		t.Error("NeedsMarketValue should only be true for discount rules")
+	}
+	if !NeedsStickerValue([]Rule{{MinStickerValue: 5}}) || NeedsStickerValue(rules) {
+		t.Error("NeedsStickerValue should only be true for sticker rules")
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	err := os.WriteFile(path, []byte(`[
+		{"name": "blue gems", "item": "Case Hardened", "seeds": [661, 151], "max_price": 2000},
+		{"name": "low float", "wears": ["Factory New"], "max_float": 0.01}
+	]`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(rules) != 2 || rules[0].Seeds[0] != 661 || rules[1].Wears[0] != factoryNew {
+		t.Errorf("got %+v", rules)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	for _, rules := range [][]Rule{
+		{{Item: "unnamed"}},
+		{{Name: "twice"}, {Name: "twice"}},
+		{{Name: "backwards", MinFloat: 0.5, MaxFloat: 0.1}},
+		{{Name: "bad wear", Wears: []AssetWear{"Mint"}}},
+	} {
+		if err := ValidateRules(rules); err == nil {
+			t.Errorf("%+v: expected an error", rules)
+		}
+	}
+}
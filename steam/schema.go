@@ -0,0 +1,80 @@
+package steam
+
+import (
+	"strings"
+
+	"eiffel65/float"
+	"eiffel65/schema"
+)
+
+// wearRanges are the float values covered by each wear, from the lowest up to
+// but not including the highest.
+var wearRanges = map[AssetWear][2]float64{
+	factoryNew:   {0, 0.07},
+	minimalWear:  {0.07, 0.15},
+	fieldTested:  {0.15, 0.38},
+	wellWorn:     {0.38, 0.45},
+	battleScared: {0.45, 1},
+}
+
+// schemaAssetType converts a schema weapon kind into an AssetType.
+func schemaAssetType(kind string) AssetType {
+	switch kind {
+	case schema.KindKnife:
+		return knifeAsset
+	case schema.KindGloves:
+		return glovesAsset
+	}
+	return weaponAsset
+}
+
+// fillFromSchema completes the names and rarity of an inspected asset from
+// the bundled item schema when the float API leaves them out.
+func (client *Client) fillFromSchema(assetFloat *float.AssetFloat) {
+	itemSchema, err := schema.Default()
+	if err != nil {
+		client.logger().Error("failed to load item schema", "error", err)
+		return
+	}
+
+	if weapon, ok := itemSchema.Weapon(assetFloat.DefIndex); ok && assetFloat.WeaponType == "" {
+		assetFloat.WeaponType = weapon.Name
+	}
+	if paintKit, ok := itemSchema.PaintKit(assetFloat.PaintIndex); ok && assetFloat.ItemName == "" {
+		assetFloat.ItemName = paintKit.Name
+	}
+	if skin, ok := itemSchema.Skin(assetFloat.DefIndex, assetFloat.PaintIndex); ok && assetFloat.Rarity == 0 {
+		assetFloat.Rarity = skin.Rarity
+	}
+}
+
+// wearPossible reports whether a skin can be found in a wear, given the wear
+// caps of its paint in the bundled item schema. Unknown paints are assumed
+// to come in every wear.
+func wearPossible(baseName string, wear AssetWear) bool {
+	wearRange, ok := wearRanges[wear]
+	if !ok {
+		return true
+	}
+
+	i := strings.LastIndex(baseName, "|")
+	if i < 0 {
+		return true
+	}
+
+	itemSchema, err := schema.Default()
+	if err != nil {
+		return true
+	}
+
+	paintKits := itemSchema.PaintKitsNamed(baseName[i+1:])
+	if len(paintKits) == 0 {
+		return true
+	}
+	for _, paintKit := range paintKits {
+		if paintKit.WearMin < wearRange[1] && paintKit.WearMax > wearRange[0] {
+			return true
+		}
+	}
+	return false
+}
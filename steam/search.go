@@ -0,0 +1,216 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// exteriorTags are the market search category tags for each wear.
+var exteriorTags = map[AssetWear]string{
+	factoryNew:   "tag_WearCategory0",
+	minimalWear:  "tag_WearCategory1",
+	fieldTested:  "tag_WearCategory2",
+	wellWorn:     "tag_WearCategory3",
+	battleScared: "tag_WearCategory4",
+}
+
+// SearchFilters narrows down a market search.
+type SearchFilters struct {
+	// Exterior limits results to the given wears.
+	Exterior []AssetWear
+	// Tags limits results by market category, keyed by the category name,
+	// e.g. "Type": {"tag_CSGO_Type_Knife"} or "Rarity": {"tag_Rarity_Ancient_Weapon"}.
+	Tags map[string][]string
+	// SortColumn is one of name, price, quantity or popular.
+	SortColumn string
+	// SortDir is asc or desc.
+	SortDir string
+	// SearchDescriptions also matches the query against item descriptions.
+	SearchDescriptions bool
+	// Start is the index of the first result to return.
+	Start int
+	// Count is how many results to return, fetched a page at a time.
+	Count int
+}
+
+// SearchPayload is a page of market search results.
+type SearchPayload struct {
+	Success    bool           `json:"success"`
+	Start      int            `json:"start"`
+	PageSize   int            `json:"pagesize"`
+	TotalCount int            `json:"total_count"`
+	Results    []SearchResult `json:"results"`
+}
+
+// SearchResult is an item on the market matching a search, with a summary of
+// its listings.
+type SearchResult struct {
+	Name             string                 `json:"name,omitempty"`
+	HashName         string                 `json:"hash_name,omitempty"`
+	SellListings     int                    `json:"sell_listings"`
+	SellPrice        int                    `json:"sell_price"`
+	SellPriceText    string                 `json:"sell_price_text,omitempty"`
+	SalePriceText    string                 `json:"sale_price_text,omitempty"`
+	AssetDescription SearchAssetDescription `json:"asset_description,omitempty"`
+}
+
+// SearchAssetDescription describes the item behind a search result.
+type SearchAssetDescription struct {
+	ClassID        string `json:"classid,omitempty"`
+	InstanceID     string `json:"instanceid,omitempty"`
+	IconURL        string `json:"icon_url,omitempty"`
+	Name           string `json:"name,omitempty"`
+	Type           string `json:"type,omitempty"`
+	MarketName     string `json:"market_name,omitempty"`
+	MarketHashName string `json:"market_hash_name,omitempty"`
+	Tradable       int    `json:"tradable,omitempty"`
+	Commodity      int    `json:"commodity,omitempty"`
+}
+
+// Search looks up items on the Steam market matching the query text, fetching
+// as many pages as needed to return filters.Count results.
+func (client *Client) Search(query string, filters SearchFilters) (*SearchPayload, error) {
+	count := filters.Count
+	if count <= 0 {
+		count = marketDefaultPageSize
+	}
+
+	searchPayload := SearchPayload{Start: filters.Start}
+	start := filters.Start
+	for len(searchPayload.Results) < count {
+		pageSize := count - len(searchPayload.Results)
+		if pageSize > marketMaxPageSize {
+			pageSize = marketMaxPageSize
+		}
+
+		page, err := client.searchPage(query, filters, start, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		searchPayload.Success = page.Success
+		searchPayload.TotalCount = page.TotalCount
+		searchPayload.Results = append(searchPayload.Results, page.Results...)
+
+		start += len(page.Results)
+		if len(page.Results) == 0 || start >= page.TotalCount {
+			break
+		}
+	}
+	searchPayload.PageSize = len(searchPayload.Results)
+
+	return &searchPayload, nil
+}
+
+// searchPage fetches a single page of market search results.
+func (client *Client) searchPage(query string, filters SearchFilters, start, count int) (*SearchPayload, error) {
+	searchURL, err := url.Parse(fmt.Sprintf("%s/%s", client.MarketBaseURL, marketSearchPath))
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("query", query)
+	params.Add("appid", csgoAppID)
+	params.Add("start", strconv.Itoa(start))
+	params.Add("count", strconv.Itoa(count))
+	params.Add("norender", "1")
+	if filters.SearchDescriptions {
+		params.Add("search_descriptions", "1")
+	}
+	if filters.SortColumn != "" {
+		params.Add("sort_column", filters.SortColumn)
+	}
+	if filters.SortDir != "" {
+		params.Add("sort_dir", filters.SortDir)
+	}
+	for _, wear := range filters.Exterior {
+		tag, ok := exteriorTags[wear]
+		if !ok {
+			return nil, fmt.Errorf("no search exterior for wear %q", wear)
+		}
+		params.Add("category_"+csgoAppID+"_Exterior[]", tag)
+	}
+	for category, tags := range filters.Tags {
+		for _, tag := range tags {
+			params.Add("category_"+csgoAppID+"_"+category+"[]", tag)
+		}
+	}
+	searchURL.RawQuery = params.Encode()
+
+	client.logger().Debug("requesting market search", "url", searchURL.String())
+
+	response, err := client.getMarket(searchURL.String())
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	err = checkResponseStatus(response.StatusCode)
+	if err != nil {
+		return nil, err
+	}
+
+	searchPayload := SearchPayload{}
+	err = json.NewDecoder(response.Body).Decode(&searchPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	if !searchPayload.Success {
+		return nil, fmt.Errorf("market search for %q was unsuccessful", query)
+	}
+
+	return &searchPayload, nil
+}
+
+// NewAssetFromMarketName looks up the market listings for an exact market
+// hash name, such as one returned by Search.
+func (client *Client) NewAssetFromMarketName(marketName string, listings int) (*[]SimpleAsset, error) {
+	wear := parseMarketNameWear(marketName)
+	isStatTrak := strings.Contains(marketName, statTrak)
+	isSouvenir := strings.HasPrefix(marketName, souvenir+" ")
+
+	simpleAsset := SimpleAsset{
+		Name:        marketName,
+		EncodedName: url.PathEscape(marketName),
+		Variant:     AssetVariant{Wear: wear, StatTrak: isStatTrak, Souvenir: isSouvenir}.String(),
+		Type:        guessAssetType(marketName, wear),
+		Quality: AssetQuality{
+			Wear: wear,
+		},
+	}
+
+	return client.listAssets(simpleAsset, listings)
+}
+
+// parseMarketNameWear reads the wear from the end of a market name.
+func parseMarketNameWear(marketName string) AssetWear {
+	for wear := range exteriorTags {
+		if strings.HasSuffix(marketName, " ("+string(wear)+")") {
+			return wear
+		}
+	}
+	return ""
+}
+
+// guessAssetType works out the kind of item from its market name.
+func guessAssetType(marketName string, wear AssetWear) AssetType {
+	switch {
+	case strings.HasPrefix(marketName, stickerPrefix):
+		return stickerAsset
+	case strings.HasPrefix(marketName, starPrefix) && strings.Contains(marketName, "Gloves"),
+		strings.HasPrefix(marketName, starPrefix) && strings.Contains(marketName, "Hand Wraps"):
+		return glovesAsset
+	case strings.HasPrefix(marketName, starPrefix):
+		return knifeAsset
+	case wear != "":
+		return weaponAsset
+	case strings.HasSuffix(marketName, "Case"):
+		return caseAsset
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package steam
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		filters  SearchFilters
+		want     int
+		requests []url.Values
+	}{
+		{"default count", 230, SearchFilters{}, 25, []url.Values{
+			{"query": {"case hardened"}, "appid": {"730"}, "start": {"0"}, "count": {"25"}, "norender": {"1"}},
+		}},
+		{"filters", 230, SearchFilters{
+			Exterior:           []AssetWear{factoryNew, minimalWear},
+			Tags:               map[string][]string{"Type": {"tag_CSGO_Type_Knife"}},
+			SortColumn:         "price",
+			SortDir:            "asc",
+			SearchDescriptions: true,
+			Count:              5,
+		}, 5, []url.Values{{
+			"query":                   {"case hardened"},
+			"appid":                   {"730"},
+			"start":                   {"0"},
+			"count":                   {"5"},
+			"norender":                {"1"},
+			"search_descriptions":     {"1"},
+			"sort_column":             {"price"},
+			"sort_dir":                {"asc"},
+			"category_730_Exterior[]": {"tag_WearCategory0", "tag_WearCategory1"},
+			"category_730_Type[]":     {"tag_CSGO_Type_Knife"},
+		}}},
+		{"two pages", 230, SearchFilters{Start: 10, Count: 150}, 150, []url.Values{
+			{"query": {"case hardened"}, "appid": {"730"}, "start": {"10"}, "count": {"100"}, "norender": {"1"}},
+			{"query": {"case hardened"}, "appid": {"730"}, "start": {"110"}, "count": {"50"}, "norender": {"1"}},
+		}},
+		{"fewer than asked", 40, SearchFilters{Count: 150}, 40, []url.Values{
+			{"query": {"case hardened"}, "appid": {"730"}, "start": {"0"}, "count": {"100"}, "norender": {"1"}},
+		}},
+	}
+
+	for _, test := range tests {
+		fake := &fakeSteam{SearchResults: test.total}
+		client := fake.client(t)
+
+		searchPayload, err := client.Search("case hardened", test.filters)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if len(searchPayload.Results) != test.want || searchPayload.PageSize != test.want || searchPayload.TotalCount != test.total || !searchPayload.Success {
+			t.Errorf("%s: got %d results, page size %d, total %d and success %t", test.name, len(searchPayload.Results), searchPayload.PageSize, searchPayload.TotalCount, searchPayload.Success)
+		}
+		if searchPayload.Start != test.filters.Start || (test.want > 0 && searchPayload.Results[0].HashName != "Sticker | Fake "+strconv.Itoa(test.filters.Start+1)) {
+			t.Errorf("%s: got results from %d starting with %+v", test.name, searchPayload.Start, searchPayload.Results[0])
+		}
+
+		requests := []url.Values{}
+		for _, request := range fake.Requests() {
+			path, rawQuery, _ := strings.Cut(request, "?")
+			if path != "/market/search/render" {
+				t.Errorf("%s: got a request for %s", test.name, path)
+			}
+			query, _ := url.ParseQuery(rawQuery)
+			requests = append(requests, query)
+		}
+		if !reflect.DeepEqual(requests, test.requests) {
+			t.Errorf("%s: got requests\n%v\nwant\n%v", test.name, requests, test.requests)
+		}
+	}
+}
+
+func TestSearchUnsuccessful(t *testing.T) {
+	fake := &fakeSteam{SearchResults: 5, SearchFails: true}
+	client := fake.client(t)
+
+	_, err := client.Search("case hardened", SearchFilters{})
+	if err == nil {
+		t.Error("expected an error when the search is unsuccessful")
+	}
+
+	_, err = client.Search("case hardened", SearchFilters{Exterior: []AssetWear{"Pristine"}})
+	if err == nil {
+		t.Error("expected an error for a wear without a search exterior")
+	}
+}
+
+func TestGuessAssetType(t *testing.T) {
+	for marketName, want := range map[string]AssetType{
+		"AK-47 | Case Hardened (Field-Tested)":             weaponAsset,
+		"StatTrak™ Five-SeveN | Case Hardened (Well-Worn)": weaponAsset,
+		"★ Karambit | Case Hardened (Minimal Wear)":        knifeAsset,
+		"★ Karambit":                           knifeAsset,
+		"★ Sport Gloves | Vice (Field-Tested)": glovesAsset,
+		"Sticker | Crown (Foil)":               stickerAsset,
+		"Chroma 2 Case":                        caseAsset,
+	} {
+		wear := parseMarketNameWear(marketName)
+		if got := guessAssetType(marketName, wear); got != want {
+			t.Errorf("%q: got %q, want %q", marketName, got, want)
+		}
+	}
+}
+
+func TestParseMarketNameWear(t *testing.T) {
+	if wear := parseMarketNameWear("★ StatTrak™ Karambit | Fade (Factory New)"); wear != factoryNew {
+		t.Errorf("got %q, want %q", wear, factoryNew)
+	}
+	if wear := parseMarketNameWear("★ Karambit"); wear != "" {
+		t.Errorf("got %q, want no wear", wear)
+	}
+}
@@ -0,0 +1,130 @@
+package steam
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SortKey is what listings can be sorted by.
+type SortKey string
+
+const (
+	// SortPrice sorts the cheapest listings first.
+	SortPrice SortKey = "price"
+	// SortFloat sorts the lowest floats first.
+	SortFloat SortKey = "float"
+	// SortSeed sorts by paint seed.
+	SortSeed SortKey = "seed"
+	// SortTier sorts the rarest pattern tiers first, ordinary patterns last.
+	SortTier SortKey = "tier"
+	// SortDeal sorts the highest deal scores first, then by tier and price.
+	SortDeal SortKey = "deal"
+)
+
+// Sort is a key to sort listings by and its direction.
+type Sort struct {
+	Key SortKey
+	// Reverse flips the natural order of the key, e.g. the most expensive
+	// listings first.
+	Reverse bool
+}
+
+// ParseSort reads a sort such as "price", "float:desc" or "tier:asc". Each
+// key has a natural direction, which is what asc means for it: prices, floats
+// and seeds ascend while tiers and deals start with the best.
+func ParseSort(option string) (Sort, error) {
+	key, direction := option, ""
+	if i := strings.LastIndex(option, ":"); i >= 0 {
+		key, direction = option[:i], option[i+1:]
+	}
+
+	sortBy := Sort{Key: SortKey(strings.ToLower(strings.TrimSpace(key)))}
+	switch sortBy.Key {
+	case SortPrice, SortFloat, SortSeed, SortTier, SortDeal:
+	default:
+		return Sort{}, fmt.Errorf("unknown sort %q, expected price, float, seed, tier or deal", key)
+	}
+
+	switch strings.ToLower(direction) {
+	case "", "asc":
+	case "desc":
+		sortBy.Reverse = true
+	default:
+		return Sort{}, fmt.Errorf("unknown sort direction %q, expected asc or desc", direction)
+	}
+
+	return sortBy, nil
+}
+
+// SortAssets sorts listings in place, breaking ties by listing ID so the
+// order is the same on every run.
+func SortAssets(assetList []SimpleAsset, sortBy Sort) {
+	sort.SliceStable(assetList, func(i, j int) bool {
+		a, b := assetList[i], assetList[j]
+		if sortBy.Reverse {
+			a, b = b, a
+		}
+
+		switch sortBy.Key {
+		case SortPrice:
+			if aPrice, bPrice := totalPrice(a), totalPrice(b); aPrice != bPrice {
+				return aPrice < bPrice
+			}
+		case SortFloat:
+			if a.Float.FloatValue != b.Float.FloatValue {
+				return a.Float.FloatValue < b.Float.FloatValue
+			}
+		case SortSeed:
+			if a.Float.PaintSeed != b.Float.PaintSeed {
+				return a.Float.PaintSeed < b.Float.PaintSeed
+			}
+		case SortTier:
+			if a.RarityTier != b.RarityTier {
+				return rarerTier(a.RarityTier, b.RarityTier)
+			}
+		case SortDeal:
+			if aScore, bScore := dealScoreOf(a), dealScoreOf(b); aScore != bScore {
+				return aScore > bScore
+			}
+			if a.RarityTier != b.RarityTier {
+				return rarerTier(a.RarityTier, b.RarityTier)
+			}
+			if aPrice, bPrice := totalPrice(a), totalPrice(b); aPrice != bPrice {
+				return aPrice < bPrice
+			}
+		}
+
+		// The tiebreak ignores Reverse so equal listings keep one order.
+		return listingIDLess(assetList[i], assetList[j])
+	})
+}
+
+// dealScoreOf is the listing's deal score, with unscored listings last.
+func dealScoreOf(asset SimpleAsset) float64 {
+	if asset.Deal == nil {
+		return math.Inf(-1)
+	}
+	return asset.Deal.Score
+}
+
+// totalPrice is the listing price including fees, or 0 if it is unknown.
+func totalPrice(asset SimpleAsset) float64 {
+	price, _ := strconv.ParseFloat(asset.ListingTotalPrice, 64)
+	return price
+}
+
+// listingIDLess orders listings by their numeric listing IDs, falling back
+// to asset IDs for items that are not listed.
+func listingIDLess(a, b SimpleAsset) bool {
+	aID, bID := a.ListingID, b.ListingID
+	if aID == "" && bID == "" {
+		aID, bID = a.ID, b.ID
+	}
+	if len(aID) != len(bID) {
+		return len(aID) < len(bID)
+	}
+	return aID < bID
+}
@@ -0,0 +1,71 @@
+package steam
+
+import (
+	"strings"
+	"testing"
+
+	"eiffel65/float"
+)
+
+var sortTestAssets = []SimpleAsset{
+	{ListingID: "1000", ListingTotalPrice: "50.00", RarityTier: 0, Float: float.AssetFloat{PaintSeed: 3, FloatValue: 0.30}},
+	{ListingID: "999", ListingTotalPrice: "80.00", RarityTier: 1, Float: float.AssetFloat{PaintSeed: 661, FloatValue: 0.20}},
+	{ListingID: "1002", ListingTotalPrice: "50.00", RarityTier: 2, Float: float.AssetFloat{PaintSeed: 44, FloatValue: 0.16}},
+	{ListingID: "1001", ListingTotalPrice: "120.00", RarityTier: 1, Float: float.AssetFloat{PaintSeed: 151, FloatValue: 0.20}},
+}
+
+func sortedIDs(assetList []SimpleAsset) string {
+	ids := []string{}
+	for _, asset := range assetList {
+		ids = append(ids, asset.ListingID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSortAssets(t *testing.T) {
+	tests := []struct {
+		option string
+		want   string
+	}{
+		{"price", "1000,1002,999,1001"},
+		{"price:desc", "1001,999,1000,1002"},
+		{"float", "1002,999,1001,1000"},
+		{"float:desc", "1000,999,1001,1002"},
+		{"seed", "1000,1002,1001,999"},
+		{"tier", "999,1001,1002,1000"},
+		{"tier:desc", "1000,1002,999,1001"},
+		{"deal", "999,1001,1002,1000"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.option, func(t *testing.T) {
+			sortBy, err := ParseSort(test.option)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			assetList := append([]SimpleAsset{}, sortTestAssets...)
+			SortAssets(assetList, sortBy)
+			if got := sortedIDs(assetList); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseSortInvalid(t *testing.T) {
+	for _, option := range []string{"", "name", "price:up"} {
+		if _, err := ParseSort(option); err == nil {
+			t.Errorf("%q: expected an error", option)
+		}
+	}
+}
+
+func TestListingIDLess(t *testing.T) {
+	if !listingIDLess(SimpleAsset{ListingID: "999"}, SimpleAsset{ListingID: "1000"}) {
+		t.Error("expected listing IDs to compare as numbers")
+	}
+	if !listingIDLess(SimpleAsset{ID: "5"}, SimpleAsset{ID: "6"}) {
+		t.Error("expected asset IDs to be used for unlisted items")
+	}
+}
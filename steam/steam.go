@@ -4,14 +4,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/jtrotsky/eiffel65/float"
-	"github.com/jtrotsky/eiffel65/image"
+	"eiffel65/float"
 )
 
 const (
@@ -22,6 +23,7 @@ const (
 	marketBaseURL           string    = "https://steamcommunity.com"
 	priceOverviewPath       string    = "market/priceoverview"
 	marketListingPath       string    = "market/listings"
+	marketSearchPath        string    = "market/search/render"
 	marketLanguage          string    = "en_US"
 	marketCountry           string    = "uk"
 	marketDataFormat        string    = "json"
@@ -30,6 +32,9 @@ const (
 	marketDefaultPageSize   int       = 25
 	marketMaxPageSize       int       = 100
 	statTrak                string    = "StatTrak™"
+	souvenir                string    = "Souvenir"
+	starPrefix              string    = "★"
+	stickerPrefix           string    = "Sticker |"
 	factoryNew              AssetWear = "Factory New"
 	minimalWear             AssetWear = "Minimal Wear"
 	fieldTested             AssetWear = "Field-Tested"
@@ -43,6 +48,9 @@ const (
 	tagAsset                AssetType = "tag"
 	toolAsset               AssetType = "tool"
 	weaponAsset             AssetType = "weapon"
+	knifeAsset              AssetType = "knife"
+	stickerAsset            AssetType = "sticker"
+	agentAsset              AssetType = "agent"
 	pathAssetInfo           string    = "ISteamEconomy/GetAssetClassInfo/v0001"
 	pathAssetPrices         string    = "ISteamEconomy/GetAssetPrices/v1"
 )
@@ -67,10 +75,28 @@ var (
 
 // Client is the Steam client that contains config and authentication.
 type Client struct {
-	APIKey     string
-	CSGOAppID  string
-	CDNBaseURL string
-	APIBaseURL string
+	APIKey        string
+	CSGOAppID     string
+	CDNBaseURL    string
+	APIBaseURL    string
+	MarketBaseURL string
+	FloatBaseURL  string
+	Currency      string
+	// MarketRateLimit and FloatRateLimit are the least time to leave between
+	// requests to the market and the float API, zero for no limit.
+	MarketRateLimit time.Duration
+	FloatRateLimit  time.Duration
+	// HTTPClient makes the requests to every provider, http.DefaultClient if
+	// nil.
+	HTTPClient *http.Client
+	// Logger is given the requests made and the lookups that fail,
+	// slog.Default() if nil.
+	Logger *slog.Logger
+	// PriceCache, if set, answers price overviews it looked up recently.
+	PriceCache *PriceCache
+
+	marketThrottle throttle
+	floatThrottle  throttle
 }
 
 // MarketListing is an item listed on the Steam market.
@@ -111,10 +137,13 @@ type MarketAction struct {
 // NewClient initiates a Steam client.
 func NewClient(apiKey string) *Client {
 	return &Client{
-		APIKey:     apiKey,
-		CSGOAppID:  csgoAppID,
-		CDNBaseURL: steamImageCDNBaseURL,
-		APIBaseURL: steamAPIBaseURL,
+		APIKey:        apiKey,
+		CSGOAppID:     csgoAppID,
+		CDNBaseURL:    steamImageCDNBaseURL,
+		APIBaseURL:    steamAPIBaseURL,
+		MarketBaseURL: marketBaseURL,
+		FloatBaseURL:  float.BaseURL,
+		Currency:      marketCurrency,
 	}
 }
 
@@ -182,23 +211,40 @@ type Tag struct {
 
 // SimpleAsset is a simple version of Asset.
 type SimpleAsset struct {
-	ID                string           `json:"id,omitempty"`
-	ClassID           string           `json:"class_id,omitempty"`
-	ContextID         string           `json:"context_id,omitempty"`
-	InstanceID        string           `json:"instance_id,omitempty"`
-	Name              string           `json:"name,omitempty"`
-	EncodedName       string           `json:"encoded_name,omitempty"`
-	IconURL           string           `json:"icon_url,omitempty"`
-	InspectURL        string           `json:"inspect_url,omitempty"`
-	ScreenshotURL     string           `json:"screenshot_url,omitempty"`
-	ListingID         string           `json:"listing_id,omitempty"`
-	ListingCurrency   string           `json:"listing_currency,omitempty"`
-	ListingPrice      string           `json:"listing_price,omitempty"`
-	ListingFee        string           `json:"listing_fee,omitempty"`
-	ListingTotalPrice string           `json:"listing_total_price,omitempty"`
-	Type              AssetType        `json:"type,omitempty"`
-	Quality           AssetQuality     `json:"quality,omitempty"`
-	Float             float.AssetFloat `json:"float,omitempty"`
+	ID                string `json:"id,omitempty"`
+	ClassID           string `json:"class_id,omitempty"`
+	ContextID         string `json:"context_id,omitempty"`
+	InstanceID        string `json:"instance_id,omitempty"`
+	Name              string `json:"name,omitempty"`
+	EncodedName       string `json:"encoded_name,omitempty"`
+	IconURL           string `json:"icon_url,omitempty"`
+	InspectURL        string `json:"inspect_url,omitempty"`
+	ScreenshotURL     string `json:"screenshot_url,omitempty"`
+	ListingID         string `json:"listing_id,omitempty"`
+	ListingCurrency   string `json:"listing_currency,omitempty"`
+	ListingPrice      string `json:"listing_price,omitempty"`
+	ListingFee        string `json:"listing_fee,omitempty"`
+	ListingTotalPrice string `json:"listing_total_price,omitempty"`
+	Variant           string `json:"variant,omitempty"`
+	RarityTier        int    `json:"rarity_tier,omitempty"`
+	// Marketable is set on inventory items that can be sold on the market.
+	Marketable   bool             `json:"marketable,omitempty"`
+	StickerValue float64          `json:"sticker_value,omitempty"`
+	Deal         *DealScore       `json:"deal,omitempty"`
+	Profit       *ProfitEstimate  `json:"profit,omitempty"`
+	MarketValue  AssetValue       `json:"market_value,omitempty"`
+	Type         AssetType        `json:"type,omitempty"`
+	Quality      AssetQuality     `json:"quality,omitempty"`
+	Float        float.AssetFloat `json:"float,omitempty"`
+}
+
+// ListingKey identifies a listing, falling back to the asset ID for items
+// that are not listed, such as those in an inventory.
+func ListingKey(asset SimpleAsset) string {
+	if asset.ListingID != "" {
+		return asset.ListingID
+	}
+	return asset.ID
 }
 
 // AssetQuality is the weapon condition and rarity.
@@ -215,107 +261,163 @@ type AssetValue struct {
 	Volume      string `json:"volume,omitempty"`
 }
 
-// GetMarketListing returns info about an asset listed on the Steam market.
-func (client *Client) GetMarketListing(encodedName string, listings int, debug bool) (*MarketListing, error) {
-	marketListingURL, err := url.Parse(fmt.Sprintf("%s/%s/%s/%s/render", marketBaseURL, marketListingPath, csgoAppID, encodedName))
+// GetMarketListing returns info about an asset listed on the Steam market,
+// fetching as many pages as it takes to get the listings asked for, or a
+// single page of the market's default size if that is 0.
+func (client *Client) GetMarketListing(encodedName string, listings int) (*MarketListing, error) {
+	marketListing := MarketListing{
+		Success:     true,
+		ListingInfo: map[string]Listing{},
+		Assets:      map[string]map[string]map[string]Asset{},
+	}
+
+	start := 0
+	for {
+		count := listings - len(marketListing.ListingInfo)
+		if count > marketMaxPageSize {
+			count = marketMaxPageSize
+		}
+
+		page, err := client.getMarketListingPage(encodedName, start, count)
+		if err != nil {
+			return nil, err
+		}
+		if !page.Success {
+			marketListing.Success = false
+			break
+		}
+
+		marketListing.TotalCount = page.TotalCount
+		for listingID, listing := range page.ListingInfo {
+			marketListing.ListingInfo[listingID] = listing
+		}
+		for appID, contexts := range page.Assets {
+			if marketListing.Assets[appID] == nil {
+				marketListing.Assets[appID] = map[string]map[string]Asset{}
+			}
+			for contextID, assets := range contexts {
+				if marketListing.Assets[appID][contextID] == nil {
+					marketListing.Assets[appID][contextID] = map[string]Asset{}
+				}
+				for assetID, asset := range assets {
+					marketListing.Assets[appID][contextID][assetID] = asset
+				}
+			}
+		}
+
+		start += len(page.ListingInfo)
+		if len(page.ListingInfo) == 0 || start >= page.TotalCount || len(marketListing.ListingInfo) >= listings {
+			break
+		}
+	}
+	marketListing.PageSize = len(marketListing.ListingInfo)
+
+	client.logger().Debug("got market listings", "name", encodedName, "total_count", marketListing.TotalCount, "listings", len(marketListing.ListingInfo))
+
+	return &marketListing, nil
+}
+
+// getMarketListingPage fetches a single page of the market listings of an
+// asset, count of them from start, or the market's default page size if
+// count is not positive.
+func (client *Client) getMarketListingPage(encodedName string, start, count int) (*MarketListing, error) {
+	marketListingURL, err := url.Parse(fmt.Sprintf("%s/%s/%s/%s/render", client.MarketBaseURL, marketListingPath, csgoAppID, encodedName))
 	if err != nil {
 		return nil, err
 	}
 
 	params := url.Values{}
-
-	if listings > marketDefaultPageSize {
-		params.Add("count", strconv.Itoa(marketMaxPageSize))
-	} else if listings != 0 {
-		params.Add("count", strconv.Itoa(listings))
+	if start > 0 {
+		params.Add("start", strconv.Itoa(start))
 	}
-
-	params.Add("currency", "2")
+	if count > 0 {
+		params.Add("count", strconv.Itoa(count))
+	}
+	params.Add("currency", client.Currency)
 	params.Add("format", marketDataFormat)
 	params.Add("appid", csgoAppID)
 	marketListingURL.RawQuery = params.Encode()
 
-	// DEBUG
-	if debug {
-		log.Println(marketListingURL)
-	}
+	client.logger().Debug("requesting market listings", "url", marketListingURL.String())
 
-	response, err := http.DefaultClient.Get(marketListingURL.String())
+	response, err := client.getMarket(marketListingURL.String())
 	if err != nil {
 		return nil, err
 	}
 	defer response.Body.Close()
 
-	switch response.StatusCode {
-	case 400:
-		return nil, fmt.Errorf("HTTP: %d , something failed", response.StatusCode)
-	case 401:
-		return nil, fmt.Errorf("HTTP: %d , unauthorised check token is valid", response.StatusCode)
-	case 403:
-		return nil, fmt.Errorf("HTTP: %d , forbidden check token permissions", response.StatusCode)
-	case 404:
-		return nil, fmt.Errorf("HTTP: %d , something failed", response.StatusCode)
-	case 429:
-		return nil, fmt.Errorf("HTTP: %d , rate limited", response.StatusCode)
-	case 500:
-		return nil, fmt.Errorf("HTTP: %d , something failed steam side", response.StatusCode)
+	err = checkResponseStatus(response.StatusCode)
+	if err != nil {
+		return nil, err
 	}
 
 	marketListing := MarketListing{}
 	err = json.NewDecoder(response.Body).Decode(&marketListing)
 	if err != nil {
-		return nil, err
-	}
-
-	if debug {
-		fmt.Println(marketListing.TotalCount, len(marketListing.Assets))
+		return nil, fmt.Errorf("failed to decode market listings: %s", err)
 	}
 
-	if marketListing.TotalCount > marketMaxPageSize {
-		params.Add("start", strconv.Itoa(marketMaxPageSize+1))
-		// DEBUG
-		if debug {
-			log.Println(marketListingURL)
-		}
-
-		response, err := http.DefaultClient.Get(marketListingURL.String())
-		if err != nil {
-			return nil, err
-		}
-		defer response.Body.Close()
-
-		err = json.NewDecoder(response.Body).Decode(&marketListing)
-		if err != nil {
-			return nil, err
-		}
+	return &marketListing, nil
+}
 
+// checkResponseStatus converts an unsuccessful Steam HTTP status into an error.
+func checkResponseStatus(statusCode int) error {
+	switch statusCode {
+	case 400:
+		return fmt.Errorf("HTTP: %d , something failed", statusCode)
+	case 401:
+		return fmt.Errorf("HTTP: %d , unauthorised check token is valid", statusCode)
+	case 403:
+		return fmt.Errorf("HTTP: %d , forbidden check token permissions", statusCode)
+	case 404:
+		return fmt.Errorf("HTTP: %d , something failed", statusCode)
+	case 429:
+		return fmt.Errorf("HTTP: %d , rate limited", statusCode)
+	case 500:
+		return fmt.Errorf("HTTP: %d , something failed steam side", statusCode)
 	}
-
-	return &marketListing, err
+	if statusCode < 200 || statusCode > 299 {
+		return fmt.Errorf("HTTP: %d , unexpected status", statusCode)
+	}
+	return nil
 }
 
 // NewAsset creates an asset instance.
-func (client *Client) NewAsset(name string, wearTier, listings int, isStatTrak, debug bool) (*[]SimpleAsset, error) {
-	wear := getWearTierName(wearTier)
-	marketName := formatMarketName(name, wear, isStatTrak)
+func (client *Client) NewAsset(name string, assetType AssetType, wearTier, listings int, isStatTrak, isSouvenir bool) (*[]SimpleAsset, error) {
+	return client.newAsset(name, assetType, getWearTierName(wearTier), listings, isStatTrak, isSouvenir)
+}
+
+// newAsset looks up the market listings for a single wear of an asset.
+func (client *Client) newAsset(name string, assetType AssetType, wear AssetWear, listings int, isStatTrak, isSouvenir bool) (*[]SimpleAsset, error) {
+	marketName, err := formatMarketName(name, assetType, wear, isStatTrak, isSouvenir)
+	if err != nil {
+		return nil, err
+	}
 
 	simpleAsset := SimpleAsset{
 		Name:        marketName,
 		EncodedName: url.PathEscape(marketName),
-		Type:        weaponAsset,
+		Variant:     AssetVariant{Wear: wear, StatTrak: isStatTrak, Souvenir: isSouvenir}.String(),
+		Type:        assetType,
 		Quality: AssetQuality{
 			Wear: wear,
 		},
 	}
 
+	return client.listAssets(simpleAsset, listings)
+}
+
+// listAssets fills out a copy of the given asset for each of its market
+// listings.
+func (client *Client) listAssets(simpleAsset SimpleAsset, listings int) (*[]SimpleAsset, error) {
 	// Returns a page of commmunity market listings for the given asset.
-	marketListing, err := client.GetMarketListing(simpleAsset.EncodedName, listings, debug)
+	marketListing, err := client.GetMarketListing(simpleAsset.EncodedName, listings)
 	if err != nil {
 		return nil, err
 	}
 
 	if !marketListing.Success {
-		return nil, err
+		return nil, fmt.Errorf("the market failed to list %s", simpleAsset.Name)
 	}
 
 	if len(marketListing.Assets) == 0 {
@@ -326,87 +428,70 @@ func (client *Client) NewAsset(name string, wearTier, listings int, isStatTrak,
 		return nil, err
 	}
 
-	// The ClassID is unique ID of each listing, which we do not know until
-	// it is returned in the listing summary.
-	classID := ""
-
-	// Create an empty simple asset for each listing.
-	assetListing := SimpleAsset{}
+	// The listing summaries are keyed by listing ID and the assets by asset
+	// ID, so pair them up by the asset each listing sells.
+	listingIDs := map[string]string{}
+	for listingID, listing := range marketListing.ListingInfo {
+		listingIDs[listing.Asset.ID] = listingID
+	}
 
 	// The list of simple asset listings to return at the end.
 	simpleAssetList := []SimpleAsset{}
 
-	// Loop through each asset listing, the key being the ClassID.
-	for cID, listing := range marketListing.Assets[client.CSGOAppID]["2"] {
-		classID = cID
-
+	// Loop through each asset listing, the key being the asset ID.
+	for _, listing := range marketListing.Assets[client.CSGOAppID]["2"] {
 		// Fill out the basic asset info that is the same for each listing.
-		assetListing = simpleAsset
+		assetListing := simpleAsset
 
-		assetListing.ClassID = classID
+		assetListing.ClassID = listing.ClassID
 		assetListing.ID = listing.ID
 		assetListing.ContextID = listing.ContextID
 		assetListing.InstanceID = listing.InstanceID
 		assetListing.Quality.Type = listing.Type
 
-		// Pricing from listing
-		//assetListing.ListingPrice = marketListing.ListingInfo[].Listing
+		if listingID, ok := listingIDs[assetListing.ID]; ok {
+			listingInfo := marketListing.ListingInfo[listingID]
+			assetListing.ListingID = listingID
+
+			listingPriceFloat := float64(listingInfo.Price) / 100
+			listingFeeFloat := float64(listingInfo.Fee) / 100
+
+			assetListing.ListingPrice = strconv.FormatFloat(listingPriceFloat, 'f', 2, 64)
+			assetListing.ListingFee = strconv.FormatFloat(listingFeeFloat, 'f', 2, 64)
+			assetListing.ListingTotalPrice = strconv.FormatFloat(listingPriceFloat+listingFeeFloat, 'f', 2, 64)
+
+			assetListing.ListingCurrency = client.Currency
+		}
 
 		for _, action := range listing.MarketActions {
 			if action.Name == "Inspect in Game..." {
-				assetListing.InspectURL = parseInspectURL(assetListing.ID, action.Link)
+				assetListing.InspectURL = parseInspectURL(assetListing.ListingID, assetListing.ID, action.Link)
 			}
 		}
 
+		// A listing that cannot be inspected is still worth listing, just
+		// without its float and pattern.
 		if assetListing.InspectURL != "" {
-			assetFloat, floatURL, err := float.Get(assetListing.InspectURL)
-			if err != nil {
-				log.Printf("failed get price summary: %s", err)
-				break
-			}
-
-			if debug {
-				log.Println(floatURL)
-			}
-
-			assetListing.Float = assetFloat.ItemInfo
-
-			screenshotURL, err := image.BuildURL(assetListing.Float.DefIndex, assetListing.Float.PaintIndex, assetListing.Float.PaintSeed, assetListing.InspectURL)
+			err := client.inspect(&assetListing)
 			if err != nil {
-				log.Printf("failed to get screenshot: %s", err)
-			}
-
-			if debug {
-				log.Println(screenshotURL)
-			}
-
-			assetListing.ScreenshotURL = screenshotURL
-		}
-
-		for listingID, listing := range marketListing.ListingInfo {
-			if listing.Asset.ID == assetListing.ID {
-				assetListing.ListingID = listingID
-
-				listingPriceFloat := float64(listing.Price) / 100
-				listingFeeFloat := float64(listing.Fee) / 100
-
-				assetListing.ListingPrice = strconv.FormatFloat(listingPriceFloat, 'f', 2, 64)
-				assetListing.ListingFee = strconv.FormatFloat(listingFeeFloat, 'f', 2, 64)
-				assetListing.ListingTotalPrice = strconv.FormatFloat(listingPriceFloat+listingFeeFloat, 'f', 2, 64)
-
-				assetListing.ListingCurrency = marketCurrency
+				client.logger().Warn("failed to inspect listing", "name", assetListing.Name, "listing_id", assetListing.ListingID, "error", err)
 			}
 		}
 
 		simpleAssetList = append(simpleAssetList, assetListing)
 	}
 
+	// The listings come from a map, so put them in a stable order.
+	sort.Slice(simpleAssetList, func(i, j int) bool {
+		return listingIDLess(simpleAssetList[i], simpleAssetList[j])
+	})
+
 	return &simpleAssetList, err
 }
 
 // GetAsset returns information about an individual item from the Steam market.
 func (client *Client) GetAsset(classID string) (*Asset, error) {
-	assetInfoURL, err := url.Parse(fmt.Sprintf("%s/%s", steamAPIBaseURL, pathAssetInfo))
+	assetInfoURL, err := url.Parse(fmt.Sprintf("%s/%s", client.APIBaseURL, pathAssetInfo))
 	if err != nil {
 		return nil, err
 	}
@@ -419,14 +504,20 @@ func (client *Client) GetAsset(classID string) (*Asset, error) {
 	params.Add("key", client.APIKey)
 	assetInfoURL.RawQuery = params.Encode()
 
-	log.Println(assetInfoURL)
+	// The URL carries the API key, so it is left out.
+	client.logger().Debug("requesting asset class info", "class_id", classID)
 
-	response, err := http.DefaultClient.Get(assetInfoURL.String())
+	response, err := client.httpClient().Get(assetInfoURL.String())
 	if err != nil {
 		return nil, err
 	}
 	defer response.Body.Close()
 
+	err = checkResponseStatus(response.StatusCode)
+	if err != nil {
+		return nil, err
+	}
+
 	type Payload struct {
 		Result map[string]json.RawMessage `json:"result,omitempty"`
 	}
@@ -456,7 +547,7 @@ func (client *Client) GetAsset(classID string) (*Asset, error) {
 		}
 	}
 
-	return nil, err
+	return nil, fmt.Errorf("no asset class %s", classID)
 }
 
 // getWearTierName identifies the wear quality category of an asset.
@@ -477,20 +568,8 @@ func getWearTierName(wearTier int) AssetWear {
 	return wear
 }
 
-// formatMarketName creates the Steam market-searchable name for an asset.
-func formatMarketName(baseName string, wear AssetWear, isStatTrak bool) string {
-	// StatTrak™ AK-47 | Case Hardened (Field-Tested)
-	marketName := ""
-	if isStatTrak {
-		marketName = statTrak + " "
-	}
-
-	marketName += baseName + " " + "(" + string(wear) + ")"
-	return marketName
-}
-
 // Transform converts a raw Steam asset into a simplified one.
-func (asset *Asset) Transform(debug bool) (*SimpleAsset, error) {
+func (client *Client) Transform(asset *Asset) (*SimpleAsset, error) {
 	assetSimple := SimpleAsset{}
 
 	assetSimple.Name = asset.MarketName
@@ -508,39 +587,18 @@ func (asset *Asset) Transform(debug bool) (*SimpleAsset, error) {
 	case "Base Grade Tool":
 		assetSimple.Type = toolAsset
 	default:
-		fmt.Println(asset.Type)
+		client.logger().Debug("unknown asset type", "name", asset.MarketName, "type", asset.Type)
 	}
 
 	return &assetSimple, nil
 }
 
-// parseInspectURL takes a raw inspect URL and converts it to one that can be
-// used to create an image of the skin
-// steam://rungame/730/76561202255233023/+csgo_econ_action_preview S76561198299749713A7013114583D3180113772518061157
-// https://p2p.bitskins.com/screenshot
-func parseInspectURL(assetID, rawInspectURL string) string {
-	fmt.Println(rawInspectURL)
-	inspectURL := strings.Split(rawInspectURL, "/")
-	inspectURLTrimmed := strings.TrimPrefix(inspectURL[5], "+csgo_econ_action_preview%20")
-	inspectURLJoined := "steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20"
-
-	d := strings.Index(inspectURLTrimmed, "D")
-	dID := inspectURLTrimmed[d:]
-
-	inspectURLTrimmed = strings.TrimSuffix(inspectURLTrimmed, inspectURLTrimmed[d:])
-
-	a := strings.Index(inspectURLTrimmed, "A")
-
-	inspectURLTrimmed = strings.TrimSuffix(inspectURLTrimmed, inspectURLTrimmed[a:])
-
-	aID := "A" + assetID
-
-	m := strings.Index(inspectURLTrimmed, "M")
-	mID := inspectURLTrimmed[m:]
-
-	inspectURLJoined += mID + aID + dID
-
-	return inspectURLJoined
+// parseInspectURL fills in the placeholders the market leaves in the inspect
+// links of its listings, e.g.
+// steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20M%listingid%A%assetid%D7001
+// becomes ...%20M4209238481020419851A32101873322D7001.
+func parseInspectURL(listingID, assetID, rawInspectURL string) string {
+	return strings.NewReplacer("%listingid%", listingID, "%assetid%", assetID).Replace(rawInspectURL)
 }
 
 // CheckForRarity loops through floats for market listings and highlights any
@@ -555,6 +613,49 @@ func CheckForRarity(assetList []SimpleAsset) map[string]SimpleAsset {
 	return notableListings
 }
 
+// RankHighlights orders notable listings from the rarest pattern tier down,
+// then by item rarity, then by cheapest total price.
+func RankHighlights(notableListings map[string]SimpleAsset) []SimpleAsset {
+	ranked := make([]SimpleAsset, 0, len(notableListings))
+	for _, asset := range notableListings {
+		ranked = append(ranked, asset)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.RarityTier != b.RarityTier {
+			return rarerTier(a.RarityTier, b.RarityTier)
+		}
+		if a.Float.Rarity != b.Float.Rarity {
+			return a.Float.Rarity > b.Float.Rarity
+		}
+		if aPrice, bPrice := totalPrice(a), totalPrice(b); aPrice != bPrice {
+			return aPrice < bPrice
+		}
+		return listingIDLess(a, b)
+	})
+
+	return ranked
+}
+
+// PatternTier returns how sought after the pattern of a paint seed is on a
+// weapon, from 1 for the rarest patterns, or 0 if it is not notable.
+func PatternTier(defIndex, seed int) int {
+	if rarePaintSeed(defIndex, seed) {
+		return 1
+	}
+	return 0
+}
+
+// rarerTier reports whether pattern tier a is rarer than tier b, where 0 means
+// no tier at all.
+func rarerTier(a, b int) bool {
+	if a == 0 {
+		return false
+	}
+	return b == 0 || a < b
+}
+
 // rarePaintSeed checks whether a seed exists in a list of rare ones.
 func rarePaintSeed(defIndex, seed int) bool {
 	switch defIndex {
@@ -0,0 +1,270 @@
+package steam
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"eiffel65/float"
+)
+
+func TestGetMarketListingPages(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		listings int
+		want     int
+		requests []string
+	}{
+		{"default page", 230, 0, 10, []string{"?appid=730&currency=2&format=json"}},
+		{"one page", 230, 25, 25, []string{"?appid=730&count=25&currency=2&format=json"}},
+		{"two pages", 230, 150, 150, []string{
+			"?appid=730&count=100&currency=2&format=json",
+			"?appid=730&count=50&currency=2&format=json&start=100",
+		}},
+		{"every page", 230, 300, 230, []string{
+			"?appid=730&count=100&currency=2&format=json",
+			"?appid=730&count=100&currency=2&format=json&start=100",
+			"?appid=730&count=100&currency=2&format=json&start=200",
+		}},
+		{"fewer than asked", 40, 100, 40, []string{"?appid=730&count=100&currency=2&format=json"}},
+		{"none", 0, 100, 0, []string{"?appid=730&count=100&currency=2&format=json"}},
+	}
+
+	for _, test := range tests {
+		fake := &fakeSteam{Listings: test.total}
+		client := fake.client(t)
+
+		marketListing, err := client.GetMarketListing("AK-47%20%7C%20Case%20Hardened", test.listings)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+		if len(marketListing.ListingInfo) != test.want || len(marketListing.Assets["730"]["2"]) != test.want {
+			t.Errorf("%s: got %d listings and %d assets, want %d", test.name, len(marketListing.ListingInfo), len(marketListing.Assets["730"]["2"]), test.want)
+		}
+		if marketListing.TotalCount != test.total || !marketListing.Success {
+			t.Errorf("%s: got total %d and success %v", test.name, marketListing.TotalCount, marketListing.Success)
+		}
+
+		requests := []string{}
+		for _, request := range fake.Requests() {
+			requests = append(requests, strings.TrimPrefix(request, "/market/listings/730/AK-47 | Case Hardened/render"))
+		}
+		if !reflect.DeepEqual(requests, test.requests) {
+			t.Errorf("%s: got requests %q, want %q", test.name, requests, test.requests)
+		}
+	}
+}
+
+func TestErrorStatuses(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable} {
+		fake := &fakeSteam{Listings: 5, Status: status}
+		client := fake.client(t)
+
+		_, err := client.GetMarketListing("AK-47", 5)
+		if err == nil || !strings.Contains(err.Error(), "HTTP: ") {
+			t.Errorf("%d: expected an HTTP error from the listings, got %v", status, err)
+		}
+		_, err = client.GetPriceOverview("AK-47 | Case Hardened (Field-Tested)")
+		if err == nil {
+			t.Errorf("%d: expected an error from the price overview", status)
+		}
+		_, err = client.GetAsset("310776560")
+		if err == nil {
+			t.Errorf("%d: expected an error from the asset class info", status)
+		}
+		_, err = client.Inspect(fakeInspectLink)
+		if err == nil {
+			t.Errorf("%d: expected an error from the float API", status)
+		}
+	}
+}
+
+func TestNewAsset(t *testing.T) {
+	fake := &fakeSteam{Listings: 3, FloatFails: map[string]bool{"1002": true}}
+	client := fake.client(t)
+
+	assetList, err := client.NewAsset("AK-47 | Case Hardened", weaponAsset, 3, 3, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*assetList) != 3 {
+		t.Fatalf("got %d listings, want 3", len(*assetList))
+	}
+
+	first := (*assetList)[0]
+	if first.Float.PaintSeed != 1 || first.Float.FloatValue != 0.001 || first.Float.WeaponType != "AK-47" || first.ScreenshotURL == "" {
+		t.Errorf("got float %+v and screenshot %q", first.Float, first.ScreenshotURL)
+	}
+	first.Float = float.AssetFloat{}
+	first.ScreenshotURL = ""
+	want := SimpleAsset{
+		ID:                "1001",
+		ClassID:           "310776560",
+		ContextID:         "2",
+		InstanceID:        "302028390",
+		Name:              "AK-47 | Case Hardened (Field-Tested)",
+		EncodedName:       "AK-47%20%7C%20Case%20Hardened%20%28Field-Tested%29",
+		InspectURL:        "steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20M1A1001D7001",
+		ListingID:         "1",
+		ListingCurrency:   "2",
+		ListingPrice:      "0.87",
+		ListingFee:        "0.13",
+		ListingTotalPrice: "1.00",
+		Variant:           "Field-Tested",
+		Type:              weaponAsset,
+		Quality:           AssetQuality{Wear: fieldTested, Type: "Classified Rifle"},
+	}
+	if !reflect.DeepEqual(first, want) {
+		t.Errorf("got first listing\n%+v\nwant\n%+v", first, want)
+	}
+
+	// The listing the float API failed on is kept, without its float.
+	failed := (*assetList)[1]
+	if failed.ListingID != "2" || failed.Float.PaintSeed != 0 || failed.ListingTotalPrice != "2.00" {
+		t.Errorf("got uninspected listing %+v", failed)
+	}
+	if third := (*assetList)[2]; third.Float.PaintSeed != 3 {
+		t.Errorf("expected the listing after the failure to be inspected, got %+v", third)
+	}
+}
+
+func TestNewAssetNoListings(t *testing.T) {
+	fake := &fakeSteam{}
+	client := fake.client(t)
+
+	assetList, err := client.NewAsset("AK-47 | Case Hardened", weaponAsset, 3, 3, false, false)
+	if err != nil || assetList != nil {
+		t.Errorf("got %v, %v, want no listings and no error", assetList, err)
+	}
+}
+
+func TestGetPriceOverview(t *testing.T) {
+	fake := &fakeSteam{}
+	client := fake.client(t)
+
+	assetValue, err := client.GetPriceOverview("AK-47 | Case Hardened (Field-Tested)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *assetValue != (AssetValue{Currency: "2", LowestPrice: "£10.00", MedianPrice: "£12.50", Volume: "42"}) {
+		t.Errorf("got %+v", assetValue)
+	}
+
+	_, err = client.GetPriceOverview("Unknown Item")
+	if err == nil {
+		t.Error("expected an error for an item the market does not find")
+	}
+}
+
+func TestGetAsset(t *testing.T) {
+	fake := &fakeSteam{}
+	client := fake.client(t)
+
+	asset, err := client.GetAsset("310776560")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset.MarketHashName != "AK-47 | Case Hardened (Field-Tested)" || asset.Type != "Classified Rifle" {
+		t.Errorf("got %+v", asset)
+	}
+
+	_, err = client.GetAsset("1")
+	if err == nil {
+		t.Error("expected an error for a missing asset class")
+	}
+
+	client.APIKey = ""
+	_, err = client.GetAsset("310776560")
+	if err == nil {
+		t.Error("expected an error without an API key")
+	}
+}
+
+func TestParseInspectURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{
+			"steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20M%listingid%A%assetid%D7001",
+			"steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20M4209238481020419851A32101873322D7001",
+		},
+		{
+			"steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20S76561198299749713A7013114583D3180113772518061157",
+			"steam://rungame/730/76561202255233023/+csgo_econ_action_preview%20S76561198299749713A7013114583D3180113772518061157",
+		},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := parseInspectURL("4209238481020419851", "32101873322", test.raw); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.raw, got, test.want)
+		}
+	}
+}
+
+func TestGetWearTierName(t *testing.T) {
+	tests := []struct {
+		wearTier int
+		want     AssetWear
+	}{
+		{0, ""},
+		{1, factoryNew},
+		{2, minimalWear},
+		{3, fieldTested},
+		{4, wellWorn},
+		{5, battleScared},
+		{6, ""},
+	}
+
+	for _, test := range tests {
+		if got := getWearTierName(test.wearTier); got != test.want {
+			t.Errorf("%d: got %q, want %q", test.wearTier, got, test.want)
+		}
+	}
+}
+
+func TestRarity(t *testing.T) {
+	tests := []struct {
+		name      string
+		defIndex  int
+		paintSeed int
+		want      bool
+	}{
+		{"AK-47 blue gem", 7, 661, true},
+		{"AK-47 other blue gem", 7, 151, true},
+		{"AK-47 plain", 7, 12, false},
+		{"Falchion rare", 512, 4, true},
+		{"Falchion plain", 512, 5, false},
+		{"AK-47 seed on another weapon", 60, 661, false},
+		{"not inspected", 0, 0, false},
+	}
+
+	assetList := []SimpleAsset{}
+	want := map[string]bool{}
+	for _, test := range tests {
+		if got := rarePaintSeed(test.defIndex, test.paintSeed); got != test.want {
+			t.Errorf("%s: got rare %v, want %v", test.name, got, test.want)
+		}
+		wantTier := 0
+		if test.want {
+			wantTier = 1
+			want[test.name] = true
+		}
+		if got := PatternTier(test.defIndex, test.paintSeed); got != wantTier {
+			t.Errorf("%s: got tier %d, want %d", test.name, got, wantTier)
+		}
+		assetList = append(assetList, SimpleAsset{ID: test.name, Float: float.AssetFloat{DefIndex: test.defIndex, PaintSeed: test.paintSeed}})
+	}
+
+	got := map[string]bool{}
+	for id := range CheckForRarity(assetList) {
+		got[id] = true
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckForRarity: got %v, want %v", got, want)
+	}
+}
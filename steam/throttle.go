@@ -0,0 +1,54 @@
+package steam
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// throttle spaces out requests to a provider so it does not rate limit us.
+type throttle struct {
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until at least interval has passed since the last request.
+func (t *throttle) wait(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	delay := t.next.Sub(now)
+	t.next = t.next.Add(interval)
+	t.mu.Unlock()
+
+	time.Sleep(delay)
+}
+
+// getMarket requests a page from the market, respecting the rate limit.
+func (client *Client) getMarket(url string) (*http.Response, error) {
+	client.marketThrottle.wait(client.MarketRateLimit)
+	return client.httpClient().Get(url)
+}
+
+// logger is the logger the client logs to.
+func (client *Client) logger() *slog.Logger {
+	if client.Logger != nil {
+		return client.Logger
+	}
+	return slog.Default()
+}
+
+// httpClient is the HTTP client requests are made with.
+func (client *Client) httpClient() *http.Client {
+	if client.HTTPClient != nil {
+		return client.HTTPClient
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,144 @@
+package steam
+
+import (
+	"sort"
+)
+
+// otherCategory is the category of items whose type is not known.
+const otherCategory string = "other"
+
+// Premiums are what an item is valued at over its market price for what the
+// price overview does not see.
+type Premiums struct {
+	// Sticker is the share of the market value of its applied stickers an
+	// item is worth more, e.g. 0.1 for a tenth.
+	Sticker float64 `json:"sticker"`
+	// Rarity is the share of its market price an item with a rare pattern
+	// is worth more, e.g. 0.5 for half as much again.
+	Rarity float64 `json:"rarity"`
+}
+
+// ItemValue is what an item is worth.
+type ItemValue struct {
+	SimpleAsset
+	Category       string  `json:"category"`
+	Priced         bool    `json:"priced"`
+	MarketPrice    float64 `json:"market_price"`
+	StickerPremium float64 `json:"sticker_premium"`
+	RarityPremium  float64 `json:"rarity_premium"`
+	Value          float64 `json:"value"`
+}
+
+// CategoryValue is what the items of a category are worth together.
+type CategoryValue struct {
+	Category string `json:"category"`
+	Items    int    `json:"items"`
+	Priced   int    `json:"priced"`
+	// Unpriced counts the marketable items whose price could not be looked
+	// up, so the value is short by what they are worth.
+	Unpriced       int     `json:"unpriced"`
+	MarketPrice    float64 `json:"market_price"`
+	StickerPremium float64 `json:"sticker_premium"`
+	RarityPremium  float64 `json:"rarity_premium"`
+	Value          float64 `json:"value"`
+}
+
+// Valuation is what a set of items is worth, item by item, by category and
+// in total.
+type Valuation struct {
+	Currency   string          `json:"currency"`
+	Premiums   Premiums        `json:"premiums"`
+	Items      []ItemValue     `json:"items"`
+	Categories []CategoryValue `json:"categories"`
+	Total      CategoryValue   `json:"total"`
+}
+
+// PriceInventory prices the marketable items of an inventory from the price
+// overview, and the stickers applied to them, looking each item and sticker
+// up once.
+func (client *Client) PriceInventory(assetList []SimpleAsset) {
+	marketable := []SimpleAsset{}
+	indexes := []int{}
+	for i, asset := range assetList {
+		if asset.Marketable {
+			marketable = append(marketable, asset)
+			indexes = append(indexes, i)
+		}
+	}
+
+	client.PriceAssets(marketable)
+	client.PriceStickers(marketable)
+	for i, asset := range marketable {
+		assetList[indexes[i]] = asset
+	}
+}
+
+// ValueAssets works out what items priced by PriceAssets are worth with the
+// premiums, most valuable first, and totals them by category. Items without
+// a price are listed but count for nothing.
+func ValueAssets(assetList []SimpleAsset, premiums Premiums, currency string) Valuation {
+	valuation := Valuation{
+		Currency:   currency,
+		Premiums:   premiums,
+		Items:      []ItemValue{},
+		Categories: []CategoryValue{},
+		Total:      CategoryValue{Category: "total"},
+	}
+
+	categories := map[string]*CategoryValue{}
+	for _, asset := range assetList {
+		itemValue := ItemValue{SimpleAsset: asset, Category: string(asset.Type)}
+		if itemValue.Category == "" {
+			itemValue.Category = otherCategory
+		}
+
+		if price, err := asset.MarketValue.Price(); err == nil && price > 0 {
+			itemValue.Priced = true
+			itemValue.MarketPrice = roundCents(price)
+			itemValue.StickerPremium = roundCents(asset.StickerValue * premiums.Sticker)
+			if asset.RarityTier > 0 {
+				itemValue.RarityPremium = roundCents(price * premiums.Rarity)
+			}
+			itemValue.Value = roundCents(itemValue.MarketPrice + itemValue.StickerPremium + itemValue.RarityPremium)
+		}
+		valuation.Items = append(valuation.Items, itemValue)
+
+		category, ok := categories[itemValue.Category]
+		if !ok {
+			category = &CategoryValue{Category: itemValue.Category}
+			categories[itemValue.Category] = category
+		}
+		category.add(itemValue)
+		valuation.Total.add(itemValue)
+	}
+
+	for _, category := range categories {
+		valuation.Categories = append(valuation.Categories, *category)
+	}
+	sort.Slice(valuation.Categories, func(i, j int) bool {
+		a, b := valuation.Categories[i], valuation.Categories[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		return a.Category < b.Category
+	})
+	sort.SliceStable(valuation.Items, func(i, j int) bool {
+		return valuation.Items[i].Value > valuation.Items[j].Value
+	})
+
+	return valuation
+}
+
+// add counts an item towards the category.
+func (category *CategoryValue) add(itemValue ItemValue) {
+	category.Items++
+	if itemValue.Priced {
+		category.Priced++
+	} else if itemValue.Marketable {
+		category.Unpriced++
+	}
+	category.MarketPrice = roundCents(category.MarketPrice + itemValue.MarketPrice)
+	category.StickerPremium = roundCents(category.StickerPremium + itemValue.StickerPremium)
+	category.RarityPremium = roundCents(category.RarityPremium + itemValue.RarityPremium)
+	category.Value = roundCents(category.Value + itemValue.Value)
+}
@@ -0,0 +1,90 @@
+package steam
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"eiffel65/float"
+)
+
+func TestValueAssets(t *testing.T) {
+	assetList := []SimpleAsset{
+		{ID: "1", Type: weaponAsset, MarketValue: AssetValue{MedianPrice: "£12.50"}},
+		{ID: "2", Type: weaponAsset, MarketValue: AssetValue{MedianPrice: "£100.00"}, RarityTier: 1, StickerValue: 40},
+		{ID: "3", Type: knifeAsset, MarketValue: AssetValue{LowestPrice: "£80.00"}},
+		{ID: "4", Type: caseAsset},
+		{ID: "5", MarketValue: AssetValue{MedianPrice: "£0.03"}},
+		// A marketable item whose price overview failed.
+		{ID: "6", Type: knifeAsset, Marketable: true},
+	}
+
+	valuation := ValueAssets(assetList, Premiums{Sticker: 0.1, Rarity: 0.5}, "GBP")
+
+	gotItems := []string{}
+	for _, item := range valuation.Items {
+		gotItems = append(gotItems, item.ID)
+	}
+	if want := []string{"2", "3", "1", "5", "4", "6"}; !reflect.DeepEqual(gotItems, want) {
+		t.Errorf("got items in order %v, want %v", gotItems, want)
+	}
+
+	rare := valuation.Items[0]
+	if rare.MarketPrice != 100 || rare.StickerPremium != 4 || rare.RarityPremium != 50 || rare.Value != 154 {
+		t.Errorf("got rare item valued %+v", rare)
+	}
+	if unpriced := valuation.Items[4]; unpriced.Priced || unpriced.Value != 0 || unpriced.Category != "case" {
+		t.Errorf("got unpriced item %+v", unpriced)
+	}
+
+	wantCategories := []CategoryValue{
+		{Category: "weapon", Items: 2, Priced: 2, MarketPrice: 112.5, StickerPremium: 4, RarityPremium: 50, Value: 166.5},
+		{Category: "knife", Items: 2, Priced: 1, Unpriced: 1, MarketPrice: 80, Value: 80},
+		{Category: "other", Items: 1, Priced: 1, MarketPrice: 0.03, Value: 0.03},
+		{Category: "case", Items: 1},
+	}
+	if !reflect.DeepEqual(valuation.Categories, wantCategories) {
+		t.Errorf("got categories\n%+v\nwant\n%+v", valuation.Categories, wantCategories)
+	}
+
+	wantTotal := CategoryValue{Category: "total", Items: 6, Priced: 4, Unpriced: 1, MarketPrice: 192.53, StickerPremium: 4, RarityPremium: 50, Value: 246.53}
+	if valuation.Total != wantTotal {
+		t.Errorf("got total %+v, want %+v", valuation.Total, wantTotal)
+	}
+}
+
+func TestPriceInventory(t *testing.T) {
+	fake := &fakeSteam{}
+	client := fake.client(t)
+	client.PriceCache = NewPriceCache(0)
+
+	stickers := []float.Sticker{{Name: "Crown (Foil)"}, {Name: "Crown (Foil)"}}
+	assetList := []SimpleAsset{
+		{ID: "1", Name: "AK-47 | Case Hardened (Field-Tested)", Marketable: true, Float: float.AssetFloat{Stickers: stickers}},
+		{ID: "2", Name: "AK-47 | Case Hardened (Field-Tested)", Marketable: true},
+		{ID: "3", Name: "5 Year Veteran Coin"},
+	}
+	client.PriceInventory(assetList)
+
+	if assetList[0].MarketValue.MedianPrice != "£12.50" || assetList[0].StickerValue != 25 {
+		t.Errorf("got first item priced %+v with stickers worth %v", assetList[0].MarketValue, assetList[0].StickerValue)
+	}
+	if assetList[1].MarketValue.MedianPrice != "£12.50" {
+		t.Errorf("got second item priced %+v", assetList[1].MarketValue)
+	}
+	if assetList[2].MarketValue != (AssetValue{}) {
+		t.Errorf("expected the unmarketable item to be left unpriced, got %+v", assetList[2].MarketValue)
+	}
+
+	// Pricing again is answered from the cache.
+	client.PriceInventory(assetList)
+	priceOverviews := 0
+	for _, request := range fake.Requests() {
+		if strings.HasPrefix(request, "/market/priceoverview") {
+			priceOverviews++
+		}
+	}
+	if priceOverviews != 2 {
+		t.Errorf("got %d price overview requests, want one for the item and one for the sticker", priceOverviews)
+	}
+}
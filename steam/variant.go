@@ -0,0 +1,149 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// AllWearTiers lists every wear tier from Factory New to Battle-Scarred.
+var AllWearTiers = []int{1, 2, 3, 4, 5}
+
+// AssetVariant is a single wear and StatTrak combination of an asset.
+type AssetVariant struct {
+	Wear     AssetWear
+	StatTrak bool
+	Souvenir bool
+}
+
+// String names the variant the way it appears in the market name, e.g.
+// "StatTrak™ Field-Tested".
+func (variant AssetVariant) String() string {
+	parts := []string{}
+	if variant.StatTrak {
+		parts = append(parts, statTrak)
+	}
+	if variant.Souvenir {
+		parts = append(parts, souvenir)
+	}
+	if variant.Wear != "" {
+		parts = append(parts, string(variant.Wear))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseWearTiers converts a wear tier option such as "3", "1,2" or "all" into
+// a list of wear tiers, which the caller is free to change.
+func ParseWearTiers(option string) ([]int, error) {
+	option = strings.TrimSpace(option)
+	if strings.EqualFold(option, "all") {
+		return append([]int(nil), AllWearTiers...), nil
+	}
+
+	wearTiers := []int{}
+	seen := map[int]bool{}
+	for _, field := range strings.Split(option, ",") {
+		wearTier, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || wearTier < 0 || wearTier > 5 {
+			return nil, fmt.Errorf("invalid wear tier %q, expected 0-5 or all", field)
+		}
+		if !seen[wearTier] {
+			seen[wearTier] = true
+			wearTiers = append(wearTiers, wearTier)
+		}
+	}
+
+	return wearTiers, nil
+}
+
+// NewVariants creates every combination of the given wear tiers with the
+// StatTrak options, e.g. both true and false to query either.
+func NewVariants(wearTiers []int, isSouvenir bool, statTrakOptions ...bool) []AssetVariant {
+	variants := []AssetVariant{}
+	for _, isStatTrak := range statTrakOptions {
+		for _, wearTier := range wearTiers {
+			variants = append(variants, AssetVariant{
+				Wear:     getWearTierName(wearTier),
+				StatTrak: isStatTrak,
+				Souvenir: isSouvenir,
+			})
+		}
+	}
+	return variants
+}
+
+// NewAssetVariants looks up the market listings of several variants of an
+// asset concurrently and combines them into a single list, with each listing
+// tagged by its variant. Variants that fail are logged and skipped unless
+// every one of them fails. It also returns the market names of the variants
+// that failed, even when they all did, as their listings may still be up.
+func (client *Client) NewAssetVariants(name string, assetType AssetType, variants []AssetVariant, listings int) (*[]SimpleAsset, []string, error) {
+	if len(variants) == 0 {
+		return nil, nil, errors.New("no variants to query")
+	}
+
+	// Skip wears the paint never comes in, such as a Battle-Scarred Fade.
+	possibleVariants := []AssetVariant{}
+	for _, variant := range variants {
+		if wearPossible(name, variant.Wear) {
+			possibleVariants = append(possibleVariants, variant)
+		} else {
+			client.logger().Debug("skipping a wear the item does not come in", "name", name, "variant", variant.String())
+		}
+	}
+	if len(possibleVariants) == 0 {
+		return nil, nil, fmt.Errorf("%s does not come in any of the requested wears", name)
+	}
+	variants = possibleVariants
+
+	results := make([]*[]SimpleAsset, len(variants))
+	errs := make([]error, len(variants))
+
+	var wg sync.WaitGroup
+	for i, variant := range variants {
+		wg.Add(1)
+		go func(i int, variant AssetVariant) {
+			defer wg.Done()
+			results[i], errs[i] = client.newAsset(name, assetType, variant.Wear, listings, variant.StatTrak, variant.Souvenir)
+		}(i, variant)
+	}
+	wg.Wait()
+
+	// Keep the order of the variants so the output is stable, skipping any
+	// listing already seen under another variant.
+	seen := map[string]bool{}
+	simpleAssetList := []SimpleAsset{}
+	failed := []string{}
+	failedCount := 0
+	for i, result := range results {
+		if errs[i] != nil {
+			client.logger().Warn("failed to get listings", "name", name, "variant", variants[i].String(), "error", errs[i])
+			failedCount++
+			// A variant whose name cannot be formatted never has listings.
+			if marketName, err := formatMarketName(name, assetType, variants[i].Wear, variants[i].StatTrak, variants[i].Souvenir); err == nil {
+				failed = append(failed, marketName)
+			}
+			continue
+		}
+		if result == nil {
+			continue
+		}
+
+		for _, asset := range *result {
+			key := ListingKey(asset)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			simpleAssetList = append(simpleAssetList, asset)
+		}
+	}
+
+	if failedCount == len(variants) {
+		return nil, failed, fmt.Errorf("all %d variants failed, last error: %s", failedCount, errs[len(errs)-1])
+	}
+
+	return &simpleAssetList, failed, nil
+}
@@ -0,0 +1,91 @@
+package steam
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseWearTiers(t *testing.T) {
+	for option, want := range map[string][]int{
+		"3":     {3},
+		"0":     {0},
+		"1,2":   {1, 2},
+		"2, 2":  {2},
+		"all":   AllWearTiers,
+		" ALL ": AllWearTiers,
+	} {
+		got, err := ParseWearTiers(option)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", option, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %v, want %v", option, got, want)
+		}
+	}
+
+	// Changing the result leaves AllWearTiers alone.
+	all, _ := ParseWearTiers("all")
+	all[0] = 5
+	if AllWearTiers[0] == 5 {
+		t.Error("changing the wear tiers of all changed AllWearTiers")
+	}
+
+	for _, option := range []string{"", "6", "-1", "fn", "1,x"} {
+		if _, err := ParseWearTiers(option); err == nil {
+			t.Errorf("%q: expected an error", option)
+		}
+	}
+}
+
+func TestNewVariants(t *testing.T) {
+	variants := NewVariants([]int{1, 3}, false, false, true)
+	names := []string{}
+	for _, variant := range variants {
+		names = append(names, variant.String())
+	}
+
+	want := []string{"Factory New", "Field-Tested", "StatTrak™ Factory New", "StatTrak™ Field-Tested"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+}
+
+func TestNewAssetVariants(t *testing.T) {
+	fake := &fakeSteam{Listings: 2, ListingsFail: map[string]bool{"AK-47 | Case Hardened (Minimal Wear)": true}}
+	client := fake.client(t)
+
+	// The fake gives every variant the same listings, so the Well-Worn ones
+	// repeat the Field-Tested ones.
+	variants := []AssetVariant{{Wear: fieldTested}, {Wear: minimalWear}, {Wear: wellWorn}}
+	assetList, failed, err := client.NewAssetVariants("AK-47 | Case Hardened", weaponAsset, variants, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, asset := range *assetList {
+		got = append(got, asset.ListingID+" "+asset.Variant)
+	}
+	if want := []string{"1 Field-Tested", "2 Field-Tested"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got listings %q, want %q", got, want)
+	}
+	if want := []string{"AK-47 | Case Hardened (Minimal Wear)"}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("got failed %q, want %q", failed, want)
+	}
+}
+
+func TestNewAssetVariantsAllFail(t *testing.T) {
+	fake := &fakeSteam{Listings: 2, Status: http.StatusTooManyRequests}
+	client := fake.client(t)
+
+	variants := []AssetVariant{{Wear: fieldTested}, {Wear: fieldTested, StatTrak: true}}
+	assetList, failed, err := client.NewAssetVariants("AK-47 | Case Hardened", weaponAsset, variants, 2)
+	if err == nil || assetList != nil {
+		t.Errorf("got %v, %v, want an error when every variant fails", assetList, err)
+	}
+	want := []string{"AK-47 | Case Hardened (Field-Tested)", "StatTrak™ AK-47 | Case Hardened (Field-Tested)"}
+	if !reflect.DeepEqual(failed, want) {
+		t.Errorf("got failed %q, want %q", failed, want)
+	}
+}
@@ -0,0 +1,139 @@
+package steam
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Watcher repeatedly scans the market and remembers which listings it has
+// already seen, so that only newly posted listings are reported.
+type Watcher struct {
+	Interval time.Duration
+	// Logger is where failed scans are logged, slog.Default() if nil, such
+	// as the logger of the client scanning.
+	Logger *slog.Logger
+
+	mu sync.Mutex
+	// seen maps the key of each listing in the last scan to its market
+	// name.
+	seen map[string]string
+}
+
+// WatchReport holds the listings a scan found, and those it found first.
+type WatchReport struct {
+	Scan int
+	Time time.Time
+	// NewListings are the listings that were not in the last scan, empty
+	// when nothing new was posted.
+	NewListings []SimpleAsset
+	// Listings is everything the scan found, for comparing new listings
+	// against and telling which listings are gone.
+	Listings []SimpleAsset
+	// Highlights are the new listings CheckForRarity finds notable, ranked
+	// by RankHighlights.
+	Highlights []SimpleAsset
+}
+
+// NewWatcher creates a watcher that rescans on the interval.
+func NewWatcher(interval time.Duration) *Watcher {
+	return &Watcher{
+		Interval: interval,
+		seen:     map[string]string{},
+	}
+}
+
+// NewListings returns the listings that were not in the last scan and
+// remembers this scan's listings for next time, forgetting those that are
+// gone so a long running watch does not keep every listing it ever saw.
+// Listings under the market names in failed are remembered as well, as the
+// scan could not tell whether they are gone, so they are not reported again
+// once those names are looked up.
+func (watcher *Watcher) NewListings(assetList []SimpleAsset, failed []string) []SimpleAsset {
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+
+	seen := make(map[string]string, len(assetList))
+	newListings := []SimpleAsset{}
+	for _, asset := range assetList {
+		key := ListingKey(asset)
+		if _, ok := watcher.seen[key]; !ok {
+			if _, ok := seen[key]; !ok {
+				newListings = append(newListings, asset)
+			}
+		}
+		seen[key] = asset.Name
+	}
+
+	failedNames := map[string]bool{}
+	for _, name := range failed {
+		failedNames[name] = true
+	}
+	for key, name := range watcher.seen {
+		if _, ok := seen[key]; !ok && failedNames[name] {
+			seen[key] = name
+		}
+	}
+
+	watcher.seen = seen
+	return newListings
+}
+
+// Watch runs the scan straight away and then on every interval until the
+// context is cancelled, calling report after every scan with the listings
+// it found and those it found for the first time, so scans where listings
+// only disappeared are reported too. Failed scans are logged and retried on
+// the next interval. The scan returns the market names it failed to look up
+// alongside what it found, as for NewAssetVariants.
+// Without an interval it scans once.
+func (watcher *Watcher) Watch(ctx context.Context, scan func() (*[]SimpleAsset, []string, error), report func(WatchReport)) {
+	if watcher.Interval <= 0 {
+		watcher.scanOnce(1, scan, report)
+		return
+	}
+
+	ticker := time.NewTicker(watcher.Interval)
+	defer ticker.Stop()
+
+	for scanCount := 1; ; scanCount++ {
+		watcher.scanOnce(scanCount, scan, report)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scanOnce runs a single scan and reports it. A scan without results is
+// reported as finding no listings, as every listing is gone.
+func (watcher *Watcher) scanOnce(scanCount int, scan func() (*[]SimpleAsset, []string, error), report func(WatchReport)) {
+	assetList, failed, err := scan()
+	if err != nil {
+		watcher.logger().Warn("scan failed", "scan", scanCount, "error", err)
+		return
+	}
+	if assetList == nil {
+		assetList = &[]SimpleAsset{}
+	}
+
+	newListings := watcher.NewListings(*assetList, failed)
+
+	report(WatchReport{
+		Scan:        scanCount,
+		Time:        time.Now(),
+		NewListings: newListings,
+		Listings:    *assetList,
+		Highlights:  RankHighlights(CheckForRarity(newListings)),
+	})
+}
+
+// logger is the logger the watcher logs to.
+func (watcher *Watcher) logger() *slog.Logger {
+	if watcher.Logger != nil {
+		return watcher.Logger
+	}
+	return slog.Default()
+}
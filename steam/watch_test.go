@@ -0,0 +1,134 @@
+package steam
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"eiffel65/float"
+)
+
+func TestWatcherNewListings(t *testing.T) {
+	watcher := NewWatcher(time.Minute)
+
+	first := watcher.NewListings([]SimpleAsset{{ListingID: "1"}, {ListingID: "2"}}, nil)
+	if len(first) != 2 {
+		t.Fatalf("got %d new listings on the first scan, want 2", len(first))
+	}
+
+	second := watcher.NewListings([]SimpleAsset{{ListingID: "2"}, {ListingID: "3"}}, nil)
+	if len(second) != 1 || second[0].ListingID != "3" {
+		t.Errorf("got %+v on the second scan, want only listing 3", second)
+	}
+
+	// Listing 1 was not in the second scan, so it is forgotten.
+	if _, ok := watcher.seen["1"]; ok || len(watcher.seen) != 2 {
+		t.Errorf("got seen %v, want only the listings of the last scan", watcher.seen)
+	}
+}
+
+func TestWatcherNewListingsFailed(t *testing.T) {
+	watcher := NewWatcher(time.Minute)
+	watcher.NewListings([]SimpleAsset{
+		{Name: "AK-47 | Case Hardened (Field-Tested)", ListingID: "1"},
+		{Name: "AK-47 | Case Hardened (Minimal Wear)", ListingID: "2"},
+		{Name: "AK-47 | Case Hardened (Well-Worn)", ListingID: "3"},
+	}, nil)
+
+	// The Minimal Wear lookup fails, so its listing may still be up, while
+	// the Well-Worn one was looked up and is gone.
+	watcher.NewListings([]SimpleAsset{
+		{Name: "AK-47 | Case Hardened (Field-Tested)", ListingID: "1"},
+	}, []string{"AK-47 | Case Hardened (Minimal Wear)"})
+	if _, ok := watcher.seen["2"]; !ok {
+		t.Errorf("got seen %v, want the listing of the failed lookup kept", watcher.seen)
+	}
+	if _, ok := watcher.seen["3"]; ok {
+		t.Errorf("got seen %v, want the gone listing forgotten", watcher.seen)
+	}
+
+	third := watcher.NewListings([]SimpleAsset{
+		{Name: "AK-47 | Case Hardened (Field-Tested)", ListingID: "1"},
+		{Name: "AK-47 | Case Hardened (Minimal Wear)", ListingID: "2"},
+	}, nil)
+	if len(third) != 0 {
+		t.Errorf("got %+v as new once the lookup worked again, want nothing new", third)
+	}
+}
+
+func TestWatcherWatch(t *testing.T) {
+	scans := [][]SimpleAsset{
+		{{ID: "a", ListingID: "1"}},
+		{{ID: "a", ListingID: "1"}},
+		{{ID: "a", ListingID: "1"}, {ID: "b", ListingID: "2", Float: float.AssetFloat{DefIndex: 7, PaintSeed: 661}}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scanCount := 0
+	scan := func() (*[]SimpleAsset, []string, error) {
+		scanCount++
+		if scanCount > len(scans) {
+			cancel()
+			return nil, nil, errors.New("no more scans")
+		}
+		return &scans[scanCount-1], nil, nil
+	}
+
+	reports := []WatchReport{}
+	NewWatcher(time.Millisecond).Watch(ctx, scan, func(report WatchReport) {
+		reports = append(reports, report)
+	})
+
+	if len(reports) != 3 {
+		t.Fatalf("got %d reports, want one for every scan", len(reports))
+	}
+
+	// The second scan found nothing new, but is still reported so it can be
+	// recorded.
+	if reports[1].Scan != 2 || len(reports[1].NewListings) != 0 || len(reports[1].Listings) != 1 {
+		t.Errorf("got %+v for the scan without new listings", reports[1])
+	}
+	if reports[2].Scan != 3 || len(reports[2].NewListings) != 1 || reports[2].NewListings[0].ListingID != "2" {
+		t.Errorf("got %+v for the third report", reports[2])
+	}
+	if len(reports[2].Highlights) != 1 || reports[2].Highlights[0].ID != "b" {
+		t.Errorf("got %+v highlights, want the rare seed", reports[2].Highlights)
+	}
+}
+
+func TestWatcherWatchEmptied(t *testing.T) {
+	watcher := NewWatcher(0)
+	watcher.NewListings([]SimpleAsset{{ListingID: "1"}}, nil)
+
+	// A scan without results means every listing is gone.
+	reports := []WatchReport{}
+	watcher.Watch(context.Background(), func() (*[]SimpleAsset, []string, error) { return nil, nil, nil }, func(report WatchReport) {
+		reports = append(reports, report)
+	})
+
+	if len(reports) != 1 || reports[0].Listings == nil || len(reports[0].Listings) != 0 || len(reports[0].NewListings) != 0 {
+		t.Errorf("got %+v, want one report of an empty scan", reports)
+	}
+}
+
+func TestWatcherLogger(t *testing.T) {
+	logs := bytes.Buffer{}
+	watcher := NewWatcher(0)
+	watcher.Logger = slog.New(slog.NewTextHandler(&logs, nil)).With("request_id", "42")
+
+	watcher.Watch(context.Background(), func() (*[]SimpleAsset, []string, error) {
+		return nil, nil, errors.New("rate limited")
+	}, func(report WatchReport) {
+		t.Errorf("got a report %+v of a failed scan", report)
+	})
+
+	if !strings.Contains(logs.String(), "scan failed") || !strings.Contains(logs.String(), "request_id=42") {
+		t.Errorf("got logs %q, want the failed scan logged to the watcher's logger", logs.String())
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"eiffel65/output"
+	"eiffel65/steam"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultValueMarketRate is the least time to leave between price overviews
+// when the config sets no market rate limit, as the market answers more than
+// about 20 a minute with 429s.
+const defaultValueMarketRate = 3 * time.Second
+
+// runValue works out what the public inventory of a Steam profile is worth,
+// item by item and by category.
+func runValue(flags *flag.FlagSet, args []string) error {
+	clientFlags := clientOptions{}
+	clientFlags.register(flags)
+	format := flags.String("o", output.Table, "the output format: json, table or csv")
+	currency := flags.String("currency", "", "the currency to value in, e.g. USD, by default the currency in the config")
+	stickerPremium := flags.Float64("sticker-premium", 0.1, "the share of the value of its applied stickers an item is worth more")
+	rarityPremium := flags.Float64("rarity-premium", 0.5, "the share of its market price an item with a rare pattern is worth more")
+	priceCachePath := flags.String("price-cache", defaultPriceCachePath(), "a file to keep price overviews in between runs, empty to not keep them")
+	priceTTL := flags.Duration("price-ttl", 6*time.Hour, "how long to keep a price overview before looking it up again, 0 to keep them until they are cleared")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("please specify one SteamID64")
+	}
+
+	err := output.CheckValuationFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	settings, err := clientFlags.load()
+	if err != nil {
+		return err
+	}
+	if *currency != "" {
+		settings.Currency = *currency
+		if _, err := settings.CurrencyCode(); err != nil {
+			return err
+		}
+	}
+
+	steamClient, err := clientFlags.newClient(settings, false)
+	if err != nil {
+		return err
+	}
+
+	if steamClient.MarketRateLimit == 0 && clientFlags.replayDir == "" {
+		steamClient.MarketRateLimit = defaultValueMarketRate
+	}
+
+	steamClient.PriceCache = steam.NewPriceCache(*priceTTL)
+	if *priceCachePath != "" {
+		steamClient.PriceCache, err = steam.LoadPriceCache(*priceCachePath, *priceTTL)
+		if err != nil {
+			return err
+		}
+	}
+
+	assetList, err := steamClient.GetInventory(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to get inventory: %s", err)
+	}
+
+	// Save the prices before printing, as looking them up is the slow part.
+	steamClient.PriceInventory(*assetList)
+	err = steamClient.PriceCache.Save()
+	if err != nil {
+		return err
+	}
+
+	premiums := steam.Premiums{Sticker: *stickerPremium, Rarity: *rarityPremium}
+	valuation := steam.ValueAssets(*assetList, premiums, steam.CurrencyName(steamClient.Currency))
+	if valuation.Total.Unpriced > 0 {
+		slog.Warn("some marketable items could not be priced and count for nothing, run again to retry them", "unpriced", valuation.Total.Unpriced)
+	}
+	return output.PrintValuation(os.Stdout, *format, valuation)
+}
+
+// defaultPriceCachePath is where price overviews are kept between runs, or
+// nowhere if there is no cache directory.
+func defaultPriceCachePath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "eiffel65", "prices.json")
+}
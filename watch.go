@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"eiffel65/history"
+	"eiffel65/metrics"
+	"eiffel65/notify"
+	"eiffel65/steam"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const defaultWatchInterval = 5 * time.Minute
+
+// runWatch rescans on an interval until interrupted, printing only the
+// listings that are new since the last scan.
+func runWatch(flags *flag.FlagSet, args []string) error {
+	clientFlags := clientOptions{}
+	clientFlags.register(flags)
+	itemFlags := itemOptions{}
+	itemFlags.register(flags)
+	alertFlags := alertOptions{}
+	alertFlags.register(flags)
+	outputFlags := outputOptions{}
+	outputFlags.register(flags)
+	profitFlags := profitOptions{}
+	profitFlags.register(flags)
+	historyFlags := historyOptions{}
+	historyFlags.register(flags)
+	interval := flags.Duration("interval", defaultWatchInterval, "how often to rescan, e.g. 30s or 5m")
+	metricsAddr := flags.String("metrics", "", "serve Prometheus metrics on this address, e.g. localhost:9065")
+	flags.Parse(args)
+
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", flags.Args())
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("the -interval must be positive")
+	}
+
+	settings, err := clientFlags.load()
+	if err != nil {
+		return err
+	}
+
+	rules, err := alertFlags.loadRules(settings)
+	if err != nil {
+		return err
+	}
+
+	steamClient, err := clientFlags.newClient(settings, true)
+	if err != nil {
+		return err
+	}
+
+	notifier, err := alertFlags.alertSinks(settings, rules)
+	if err != nil {
+		return err
+	}
+
+	printer, err := outputFlags.printer()
+	if err != nil {
+		return err
+	}
+
+	db, err := historyFlags.open(settings)
+	if err != nil {
+		return err
+	}
+	if db != nil {
+		defer db.Close()
+	}
+
+	scan, err := itemFlags.scanner(flags, steamClient, settings)
+	if err != nil {
+		return err
+	}
+
+	if *metricsAddr != "" {
+		go serveMetrics(*metricsAddr)
+	}
+
+	watch(steamClient, rules, profitFlags, scan, notifier, printer, db, *interval)
+	return nil
+}
+
+// serveMetrics serves /metrics on the address for as long as watch mode runs.
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	slog.Info("serving metrics", "url", "http://"+addr+"/metrics")
+	err := http.ListenAndServe(addr, mux)
+	slog.Error("failed to serve metrics", "error", err)
+}
+
+// watch rescans on the interval until interrupted, printing only the
+// listings that are new since the last scan and recording every scan in the
+// history, including those with nothing new, so listings that are gone are
+// marked as such.
+func watch(steamClient *steam.Client, rules []steam.Rule, profit profitOptions, scan func() (*[]steam.SimpleAsset, []string, error), notifier notify.Notifier, printer *listingPrinter, db *history.DB, interval time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("watching for new listings", "interval", interval)
+
+	watcher := steam.NewWatcher(interval)
+	watcher.Logger = steamClient.Logger
+	watcher.Watch(ctx, scan, func(report steam.WatchReport) {
+		recordScan(db, report.Listings, report.Time)
+
+		matches := findMatches(steamClient, rules, profit, report.NewListings, report.Listings)
+		sendAlerts(notifier, matches)
+
+		// The scan summary goes to the log so the output stays parseable.
+		slog.Info("scanned", "scan", report.Scan, "listings", len(report.Listings), "new_listings", len(report.NewListings), "highlights", len(matches))
+
+		if len(report.NewListings) == 0 {
+			return
+		}
+		err := printer.print(report.NewListings, matches)
+		if err != nil {
+			slog.Error("failed to print listings", "error", err)
+		}
+	})
+}